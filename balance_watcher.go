@@ -0,0 +1,215 @@
+package vtupass_go
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BalanceEvent is passed to BalanceWatcher callbacks.
+type BalanceEvent struct {
+	// Threshold is the level that was crossed.
+	Threshold Money
+	Balance   Money
+	// Runway estimates how long the balance lasts at the recent rate of
+	// spend. It is zero when there is no recent spend to go by.
+	Runway time.Duration
+	At     time.Time
+}
+
+// BalanceWatcherOptions configures a BalanceWatcher.
+type BalanceWatcherOptions struct {
+	// Interval between Balance polls. Defaults to one minute.
+	Interval time.Duration
+	// Thresholds are the balance levels that trigger callbacks.
+	Thresholds []Money
+	// SpendWindow is how far back spend is considered when estimating
+	// runway. Defaults to one hour.
+	SpendWindow time.Duration
+
+	// OnBelow is called when the balance drops below a threshold, or is
+	// already below it on the first poll.
+	OnBelow func(BalanceEvent)
+	// OnAbove is called when the balance recovers above a threshold.
+	OnAbove func(BalanceEvent)
+	// OnError is called when a poll fails.
+	OnError func(error)
+}
+
+type spendEntry struct {
+	amount Money
+	at     time.Time
+}
+
+// BalanceWatcher polls the wallet balance and fires callbacks when it
+// crosses configured thresholds.
+type BalanceWatcher struct {
+	service     *VTService
+	opts        BalanceWatcherOptions
+	started     time.Time
+	unsubscribe func()
+
+	mu      sync.Mutex
+	balance Money
+	polled  bool
+	below   map[Money]bool
+	spend   []spendEntry
+}
+
+// NewBalanceWatcher creates a watcher for the service's wallet. Spend from
+// purchases made through the service is recorded for runway estimates
+// until the watcher is closed.
+func (s *VTService) NewBalanceWatcher(opts BalanceWatcherOptions) *BalanceWatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.SpendWindow <= 0 {
+		opts.SpendWindow = time.Hour
+	}
+
+	thresholds := append([]Money(nil), opts.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] > thresholds[j] })
+	opts.Thresholds = thresholds
+
+	w := &BalanceWatcher{
+		service: s,
+		opts:    opts,
+		started: time.Now(),
+		below:   make(map[Money]bool),
+	}
+	w.unsubscribe = s.OnSpend(w.recordSpend)
+
+	return w
+}
+
+// Close stops recording the service's spend. Call it when the watcher is
+// no longer used; Run and Check keep working but runway estimates no
+// longer follow new purchases.
+func (w *BalanceWatcher) Close() {
+	w.unsubscribe()
+}
+
+// Run polls the balance every Interval until ctx is done.
+func (w *BalanceWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Check(ctx); err != nil && w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check polls the balance once and fires callbacks for crossed thresholds.
+func (w *BalanceWatcher) Check(ctx context.Context) (Money, error) {
+	wallet, err := w.service.Balance(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	balance, err := ParseMoney(wallet.Contents.Balance)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	w.mu.Lock()
+	w.balance = balance
+	w.polled = true
+	runway := w.runway(now)
+
+	var below, above []BalanceEvent
+	for _, threshold := range w.opts.Thresholds {
+		isBelow := balance < threshold
+		if isBelow == w.below[threshold] {
+			continue
+		}
+		w.below[threshold] = isBelow
+
+		event := BalanceEvent{Threshold: threshold, Balance: balance, Runway: runway, At: now}
+		if isBelow {
+			below = append(below, event)
+		} else {
+			above = append(above, event)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, event := range below {
+		if w.opts.OnBelow != nil {
+			w.opts.OnBelow(event)
+		}
+	}
+	for _, event := range above {
+		if w.opts.OnAbove != nil {
+			w.opts.OnAbove(event)
+		}
+	}
+
+	return balance, nil
+}
+
+// Balance returns the last polled balance and whether a poll has succeeded.
+func (w *BalanceWatcher) Balance() (Money, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.balance, w.polled
+}
+
+// Runway estimates how long the last polled balance lasts at the rate of
+// spend seen over SpendWindow. It returns false when there is no spend to
+// go by.
+func (w *BalanceWatcher) Runway() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	runway := w.runway(time.Now())
+	return runway, runway > 0
+}
+
+func (w *BalanceWatcher) recordSpend(amount Money, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.spend = append(w.spend, spendEntry{amount: amount, at: at})
+	if w.polled {
+		// Keep the estimate current until the next poll.
+		w.balance -= amount
+	}
+}
+
+// runway must be called with w.mu held.
+func (w *BalanceWatcher) runway(now time.Time) time.Duration {
+	cutoff := now.Add(-w.opts.SpendWindow)
+
+	kept := w.spend[:0]
+	var spent Money
+	for _, entry := range w.spend {
+		if entry.at.After(cutoff) {
+			kept = append(kept, entry)
+			spent += entry.amount
+		}
+	}
+	w.spend = kept
+
+	if spent <= 0 || w.balance <= 0 {
+		return 0
+	}
+
+	window := w.opts.SpendWindow
+	if elapsed := now.Sub(w.started); elapsed < window {
+		window = elapsed
+	}
+
+	perSecond := float64(spent) / window.Seconds()
+	return time.Duration(float64(w.balance) / perSecond * float64(time.Second))
+}
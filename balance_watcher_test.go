@@ -0,0 +1,79 @@
+package vtupass_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	for input, expected := range map[string]Money{
+		"1000":       100000,
+		"12,345.60":  1234560,
+		"₦500.5":     50050,
+		"N 20":       2000,
+		"0.015":      2,
+		"  7.10  ":   710,
+		"NGN1,000.1": 100010,
+	} {
+		m, err := ParseMoney(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, m, input)
+	}
+
+	_, err := ParseMoney("abc")
+	assert.Error(t, err)
+	assert.Equal(t, "1234.05", Money(123405).String())
+}
+
+func TestBalanceWatcherThresholds(t *testing.T) {
+	balances := []string{"5000.00", "900.00", "400.00", "2000.00"}
+
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetGetFunc(func(ctx context.Context, path string) (*http.Response, error) {
+		var wallet WalletBalance
+		wallet.Contents.Balance = balances[0]
+		balances = balances[1:]
+		return jsonResponse(http.StatusOK, wallet), nil
+	})
+
+	service := &VTService{client: mockClient}
+
+	var below, above []Money
+	watcher := service.NewBalanceWatcher(BalanceWatcherOptions{
+		Thresholds:  []Money{NewMoney(500), NewMoney(1000)},
+		SpendWindow: time.Hour,
+		OnBelow:     func(e BalanceEvent) { below = append(below, e.Threshold) },
+		OnAbove:     func(e BalanceEvent) { above = append(above, e.Threshold) },
+	})
+
+	for i := 0; i < 4; i++ {
+		_, err := watcher.Check(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []Money{NewMoney(1000), NewMoney(500)}, below)
+	assert.Equal(t, []Money{NewMoney(1000), NewMoney(500)}, above)
+
+	_, ok := watcher.Runway()
+	assert.False(t, ok)
+
+	service.recordSpend(NewMoney(100))
+	runway, ok := watcher.Runway()
+	assert.True(t, ok)
+	assert.Greater(t, runway, time.Duration(0))
+
+	balance, _ := watcher.Balance()
+	assert.Equal(t, NewMoney(1900), balance)
+
+	watcher.Close()
+	service.recordSpend(NewMoney(100))
+	balance, _ = watcher.Balance()
+	assert.Equal(t, NewMoney(1900), balance)
+	assert.Empty(t, service.spendListeners)
+}
@@ -0,0 +1,200 @@
+package billpay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// Fake is an in-memory BillPaymentProvider for tests. Payments succeed and
+// debit the wallet unless NextStatus or Err says otherwise. Set the exported
+// fields before use.
+type Fake struct {
+	// NextStatus is given to new payments. Defaults to StatusSuccessful.
+	// Failed payments do not debit the wallet.
+	NextStatus Status
+	// Err, when set, is returned by every call.
+	Err error
+
+	mu        sync.Mutex
+	balance   vt.Money
+	payments  map[string]*Payment
+	customers map[string]Customer
+	prices    map[string]vt.Money
+	seq       int
+}
+
+var _ BillPaymentProvider = (*Fake)(nil)
+
+// NewFake returns a Fake whose wallet holds balance.
+func NewFake(balance vt.Money) *Fake {
+	return &Fake{
+		balance:   balance,
+		payments:  make(map[string]*Payment),
+		customers: make(map[string]Customer),
+		prices:    make(map[string]vt.Money),
+	}
+}
+
+// AddCustomer makes customer known to Verify and TV renewals.
+func (f *Fake) AddCustomer(customer Customer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.customers[customer.CustomerID] = customer
+}
+
+// SetPrice sets the price of a data or TV plan bought without an amount.
+func (f *Fake) SetPrice(plan string, price vt.Money) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prices[plan] = price
+}
+
+// Settle moves a payment to status, e.g. to resolve a pending payment or
+// reverse a successful one. Reversals refund the wallet.
+func (f *Fake) Settle(reference string, status Status) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[reference]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPaymentNotFound, reference)
+	}
+	if payment.Status != StatusFailed && (status == StatusFailed || status == StatusReversed) {
+		f.balance += payment.Amount
+	}
+	payment.Status = status
+	return nil
+}
+
+// Payments returns every payment made, oldest first.
+func (f *Fake) Payments() []Payment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payments := make([]Payment, 0, len(f.payments))
+	for _, payment := range f.payments {
+		payments = append(payments, *payment)
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].ProviderReference < payments[j].ProviderReference
+	})
+	return payments
+}
+
+func (f *Fake) Airtime(ctx context.Context, req AirtimeRequest) (*Payment, error) {
+	return f.pay(req.Reference, req.Amount, "")
+}
+
+func (f *Fake) Data(ctx context.Context, req DataRequest) (*Payment, error) {
+	return f.pay(req.Reference, req.Amount, req.Plan)
+}
+
+func (f *Fake) Electricity(ctx context.Context, req ElectricityRequest) (*Payment, error) {
+	return f.pay(req.Reference, req.Amount, "")
+}
+
+func (f *Fake) TV(ctx context.Context, req TVRequest) (*Payment, error) {
+	amount := req.Amount
+	if req.Plan == "" && amount == 0 {
+		f.mu.Lock()
+		amount = f.customers[req.SmartcardNumber].RenewalAmount
+		f.mu.Unlock()
+	}
+	return f.pay(req.Reference, amount, req.Plan)
+}
+
+func (f *Fake) Verify(ctx context.Context, req VerifyRequest) (*Customer, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	customer, ok := f.customers[req.CustomerID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, req.CustomerID)
+	}
+	return &customer, nil
+}
+
+func (f *Fake) Requery(ctx context.Context, reference string) (*Payment, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[reference]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentNotFound, reference)
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *Fake) Balance(ctx context.Context) (vt.Money, error) {
+	if f.Err != nil {
+		return 0, f.Err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.balance, nil
+}
+
+func (f *Fake) pay(reference string, amount vt.Money, plan string) (*Payment, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.payments[reference]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+
+	if amount == 0 && plan != "" {
+		price, ok := f.prices[plan]
+		if !ok {
+			return nil, fmt.Errorf("%w: plan %q", ErrUnsupported, plan)
+		}
+		amount = price
+	}
+
+	status := f.NextStatus
+	if status == "" {
+		status = StatusSuccessful
+	}
+	if status != StatusFailed {
+		if amount > f.balance {
+			return nil, ErrInsufficientBalance
+		}
+		f.balance -= amount
+	}
+
+	f.seq++
+	if reference == "" {
+		reference = "fake-" + strconv.Itoa(f.seq)
+	}
+	payment := &Payment{
+		Reference:         reference,
+		ProviderReference: fmt.Sprintf("%012d", f.seq),
+		Status:            status,
+		Amount:            amount,
+		At:                time.Now(),
+	}
+	f.payments[reference] = payment
+
+	copied := *payment
+	return &copied, nil
+}
@@ -0,0 +1,60 @@
+package billpay
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	vt "github.com/CeoFred/vtpass-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake(vt.NewMoney(1000))
+	fake.SetPrice("mtn-1gb", vt.NewMoney(300))
+	fake.AddCustomer(Customer{CustomerID: "7027914329", Name: "ADA OBI", RenewalAmount: vt.NewMoney(500)})
+
+	var provider BillPaymentProvider = fake
+
+	airtime, err := provider.Airtime(ctx, AirtimeRequest{Reference: "a1", Network: MTN, Phone: "08011111111", Amount: vt.NewMoney(100)})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccessful, airtime.Status)
+
+	// Repeating a reference does not pay twice.
+	_, err = provider.Airtime(ctx, AirtimeRequest{Reference: "a1", Network: MTN, Phone: "08011111111", Amount: vt.NewMoney(100)})
+	require.NoError(t, err)
+
+	data, err := provider.Data(ctx, DataRequest{Network: MTN, Phone: "08011111111", Plan: "mtn-1gb"})
+	require.NoError(t, err)
+	assert.Equal(t, vt.NewMoney(300), data.Amount)
+
+	tv, err := provider.TV(ctx, TVRequest{Biller: DSTV, SmartcardNumber: "7027914329"})
+	require.NoError(t, err)
+	assert.Equal(t, vt.NewMoney(500), tv.Amount)
+
+	balance, err := provider.Balance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, vt.NewMoney(100), balance)
+
+	_, err = provider.Electricity(ctx, ElectricityRequest{Biller: "ikeja-electric", MeterNumber: "1111111111111", Amount: vt.NewMoney(200)})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	require.NoError(t, fake.Settle(tv.Reference, StatusReversed))
+	balance, _ = provider.Balance(ctx)
+	assert.Equal(t, vt.NewMoney(600), balance)
+
+	requeried, err := provider.Requery(ctx, tv.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, StatusReversed, requeried.Status)
+	assert.Len(t, fake.Payments(), 3)
+
+	_, err = provider.Verify(ctx, VerifyRequest{Biller: DSTV, CustomerID: "unknown"})
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+
+	fake.Err = errors.New("down")
+	_, err = provider.Balance(ctx)
+	assert.EqualError(t, err, "down")
+}
@@ -0,0 +1,159 @@
+// Package billpay defines a provider-agnostic contract for bill payment
+// aggregators. Business logic written against BillPaymentProvider can run
+// on VTPass, on another aggregator or on the in-memory Fake.
+package billpay
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient wallet balance")
+	ErrCustomerNotFound    = errors.New("customer not found")
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrUnsupported         = errors.New("unsupported by provider")
+)
+
+// BillPaymentProvider is implemented by every aggregator.
+type BillPaymentProvider interface {
+	Airtime(ctx context.Context, req AirtimeRequest) (*Payment, error)
+	Data(ctx context.Context, req DataRequest) (*Payment, error)
+	Electricity(ctx context.Context, req ElectricityRequest) (*Payment, error)
+	TV(ctx context.Context, req TVRequest) (*Payment, error)
+	// Verify looks up the customer behind a meter or smartcard number.
+	Verify(ctx context.Context, req VerifyRequest) (*Customer, error)
+	// Requery returns the current state of a payment by its Reference.
+	Requery(ctx context.Context, reference string) (*Payment, error)
+	// Balance returns the funds available in the wallet.
+	Balance(ctx context.Context) (vt.Money, error)
+}
+
+// Network is a mobile network operator.
+type Network string
+
+const (
+	MTN     Network = "mtn"
+	Airtel  Network = "airtel"
+	Glo     Network = "glo"
+	NineMob Network = "9mobile"
+)
+
+// Biller identifies a TV or electricity biller. Electricity billers use
+// the disco names of the vtpass package, e.g. "ikeja-electric".
+type Biller string
+
+const (
+	DSTV      Biller = "dstv"
+	GOTV      Biller = "gotv"
+	Startimes Biller = "startimes"
+	Showmax   Biller = "showmax"
+)
+
+// MeterType is an electricity metering arrangement.
+type MeterType string
+
+const (
+	Prepaid  MeterType = "prepaid"
+	Postpaid MeterType = "postpaid"
+)
+
+// Status is the normalised state of a payment.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusSuccessful Status = "successful"
+	StatusFailed     Status = "failed"
+	StatusReversed   Status = "reversed"
+)
+
+// IsFinal reports whether the provider has settled the payment.
+func (s Status) IsFinal() bool {
+	return s != StatusPending
+}
+
+// AirtimeRequest tops up a phone number.
+type AirtimeRequest struct {
+	// Reference identifies the payment for Requery. Providers generate one
+	// when empty.
+	Reference string
+	Network   Network
+	Phone     string
+	Amount    vt.Money
+}
+
+// DataRequest buys a data bundle.
+type DataRequest struct {
+	Reference string
+	Network   Network
+	Phone     string
+	// Plan is the provider's code for the bundle.
+	Plan string
+	// Amount is optional for fixed-price plans.
+	Amount vt.Money
+}
+
+// ElectricityRequest buys a prepaid token or pays a postpaid bill.
+type ElectricityRequest struct {
+	Reference   string
+	Biller      Biller
+	MeterNumber string
+	MeterType   MeterType
+	Amount      vt.Money
+	Phone       string
+}
+
+// TVRequest pays for a TV subscription.
+type TVRequest struct {
+	Reference       string
+	Biller          Biller
+	SmartcardNumber string
+	// Plan is the provider's code for the bouquet. Leave empty to renew
+	// the current bouquet for Amount.
+	Plan   string
+	Amount vt.Money
+	Phone  string
+}
+
+// VerifyRequest identifies a customer to look up.
+type VerifyRequest struct {
+	Biller Biller
+	// CustomerID is a meter or smartcard number.
+	CustomerID string
+	// MeterType is required for electricity billers.
+	MeterType MeterType
+}
+
+// Customer is a verified biller account.
+type Customer struct {
+	CustomerID string
+	Name       string
+	Address    string
+	// Arrears is the outstanding balance on postpaid meters.
+	Arrears vt.Money
+	// Plan is the current TV bouquet.
+	Plan    string
+	DueDate string
+	// RenewalAmount is the price of renewing the current TV bouquet.
+	RenewalAmount vt.Money
+}
+
+// Payment is the outcome of a purchase.
+type Payment struct {
+	Reference string
+	// ProviderReference is the aggregator's own transaction ID.
+	ProviderReference string
+	Status            Status
+	Amount            vt.Money
+	// Commission is what the provider paid the merchant for the sale.
+	Commission vt.Money
+	// Token is the electricity token, or any PIN the product delivers.
+	Token   string
+	Units   string
+	Message string
+	At      time.Time
+}
@@ -0,0 +1,229 @@
+package billpay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// VTPass adapts a VTService to BillPaymentProvider. References are used as
+// VTPass request IDs, so they must follow VTPass's request ID format or be
+// left empty.
+type VTPass struct {
+	service *vt.VTService
+}
+
+var _ BillPaymentProvider = (*VTPass)(nil)
+
+func NewVTPass(service *vt.VTService) *VTPass {
+	return &VTPass{service: service}
+}
+
+var networkServiceIDs = map[Network]string{
+	MTN:     "mtn",
+	Airtel:  "airtel",
+	Glo:     "glo",
+	NineMob: "etisalat",
+}
+
+func networkServiceID(network Network) (string, error) {
+	serviceID, ok := networkServiceIDs[network]
+	if !ok {
+		return "", fmt.Errorf("%w: network %q", ErrUnsupported, network)
+	}
+	return serviceID, nil
+}
+
+func (p *VTPass) Airtime(ctx context.Context, req AirtimeRequest) (*Payment, error) {
+	serviceID, err := networkServiceID(req.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.pay(ctx, vt.PurchaseRequest{
+		RequestID: req.Reference,
+		ServiceID: serviceID,
+		Amount:    req.Amount.Naira(),
+		Phone:     req.Phone,
+	})
+}
+
+func (p *VTPass) Data(ctx context.Context, req DataRequest) (*Payment, error) {
+	serviceID, err := networkServiceID(req.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.pay(ctx, vt.PurchaseRequest{
+		RequestID:     req.Reference,
+		ServiceID:     serviceID + "-data",
+		BillersCode:   req.Phone,
+		VariationCode: req.Plan,
+		Amount:        req.Amount.Naira(),
+		Phone:         req.Phone,
+	})
+}
+
+func (p *VTPass) Electricity(ctx context.Context, req ElectricityRequest) (*Payment, error) {
+	disco, err := vt.ParseDisco(string(req.Biller))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	meterType := vt.MeterType(req.MeterType)
+	if err := disco.ValidateMeterNumber(req.MeterNumber, meterType); err != nil {
+		return nil, err
+	}
+
+	return p.pay(ctx, vt.PurchaseRequest{
+		RequestID:     req.Reference,
+		ServiceID:     string(disco),
+		BillersCode:   req.MeterNumber,
+		VariationCode: string(meterType),
+		Amount:        req.Amount.Naira(),
+		Phone:         req.Phone,
+	})
+}
+
+func (p *VTPass) TV(ctx context.Context, req TVRequest) (*Payment, error) {
+	purchase := vt.PurchaseRequest{
+		RequestID:        req.Reference,
+		ServiceID:        string(req.Biller),
+		BillersCode:      req.SmartcardNumber,
+		Amount:           req.Amount.Naira(),
+		Phone:            req.Phone,
+		SubscriptionType: "renew",
+	}
+	if req.Plan != "" {
+		purchase.VariationCode = req.Plan
+		purchase.SubscriptionType = "change"
+	}
+	return p.pay(ctx, purchase)
+}
+
+func (p *VTPass) Verify(ctx context.Context, req VerifyRequest) (*Customer, error) {
+	var (
+		info *vt.CustomerInfo
+		err  error
+	)
+	if disco, parseErr := vt.ParseDisco(string(req.Biller)); parseErr == nil {
+		info, err = p.service.VerifyMeterNumber(ctx, req.CustomerID, vt.MeterType(req.MeterType), disco)
+	} else {
+		info, err = p.service.VerifyCustomer(ctx, string(req.Biller), req.CustomerID, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.WrongBillersCode || info.Error != "" || info.CustomerName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, req.CustomerID)
+	}
+
+	customer := &Customer{
+		CustomerID:    req.CustomerID,
+		Name:          info.CustomerName,
+		Address:       info.Address,
+		Plan:          info.CurrentBouquet,
+		DueDate:       info.DueDate,
+		RenewalAmount: vt.NewMoney(info.RenewalAmount.Float64()),
+	}
+	if info.CustomerArrears != "" {
+		if arrears, err := vt.ParseMoney(info.CustomerArrears); err == nil {
+			customer.Arrears = arrears
+		}
+	}
+	return customer, nil
+}
+
+func (p *VTPass) Requery(ctx context.Context, reference string) (*Payment, error) {
+	resp, err := p.service.QueryTransaction(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code == vt.INVALID_REQUEST_ID {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentNotFound, reference)
+	}
+
+	txn := resp.Content.Transactions
+	payment := &Payment{
+		Reference:         reference,
+		ProviderReference: txn.TransactionID,
+		Status:            normaliseStatus(resp.Status()),
+		Amount:            vt.NewMoney(firstPositive(txn.Amount, resp.Amount)),
+		Commission:        txn.CommissionAmount(),
+		Token:             resp.PurchasedCode,
+		Message:           resp.ResponseDescription,
+		At:                paidAt(resp.TransactionDate),
+	}
+	return payment, nil
+}
+
+func (p *VTPass) Balance(ctx context.Context) (vt.Money, error) {
+	balance, err := p.service.Balance(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return vt.ParseMoney(balance.Contents.Balance)
+}
+
+func (p *VTPass) pay(ctx context.Context, req vt.PurchaseRequest) (*Payment, error) {
+	if req.RequestID == "" {
+		req.RequestID = p.service.GenerateRequestID()
+	}
+
+	resp, err := p.service.Purchase(ctx, req)
+	if err != nil {
+		if vt.IsLowBalance(err) {
+			return nil, fmt.Errorf("%w: %w", ErrInsufficientBalance, err)
+		}
+		return nil, err
+	}
+
+	txn := resp.Content.Transactions
+	payment := &Payment{
+		Reference:         req.RequestID,
+		ProviderReference: txn.TransactionID,
+		Status:            normaliseStatus(resp.Status()),
+		Amount:            vt.NewMoney(firstPositive(txn.Amount, resp.Amount, vt.FlexFloat(req.Amount))),
+		Commission:        resp.Commission(),
+		Token:             resp.PurchasedCode,
+		Units:             resp.Units.String(),
+		Message:           resp.ResponseDescription,
+		At:                paidAt(resp.TransactionDate),
+	}
+
+	// Electricity tokens arrive in free text; keep the raw purchased code
+	// when no valid token can be extracted.
+	if receipt, err := vt.ParseElectricityReceipt(resp); err == nil && receipt.Token != "" {
+		payment.Token = receipt.Token
+	}
+	return payment, nil
+}
+
+func normaliseStatus(status vt.TransactionStatus) Status {
+	switch status {
+	case vt.StatusDelivered:
+		return StatusSuccessful
+	case vt.StatusFailed:
+		return StatusFailed
+	case vt.StatusReversed:
+		return StatusReversed
+	}
+	return StatusPending
+}
+
+func firstPositive(values ...vt.FlexFloat) float64 {
+	for _, v := range values {
+		if v > 0 {
+			return v.Float64()
+		}
+	}
+	return 0
+}
+
+func paidAt(date vt.TransactionDate) time.Time {
+	if at, err := date.Time(); err == nil && !at.IsZero() {
+		return at
+	}
+	return time.Now()
+}
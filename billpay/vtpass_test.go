@@ -0,0 +1,129 @@
+package billpay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	vt "github.com/CeoFred/vtpass-go"
+	"github.com/CeoFred/vtpass-go/internal/vtpasstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVTPass returns an adapter whose requests are answered by handle,
+// keyed by endpoint, with the decoded JSON body of POST requests.
+func newTestVTPass(t *testing.T, handle func(endpoint string, body map[string]interface{}) string) *VTPass {
+	return NewVTPass(vtpasstest.NewService(t, func(endpoint string, body map[string]interface{}) (int, string) {
+		return http.StatusOK, handle(endpoint, body)
+	}))
+}
+
+func TestVTPassAirtimeAndData(t *testing.T) {
+	var sent []map[string]interface{}
+	provider := newTestVTPass(t, func(endpoint string, body map[string]interface{}) string {
+		sent = append(sent, body)
+		return `{"code":"000","amount":"500.00","content":{"transactions":{"status":"delivered","transactionId":"17000","amount":500}}}`
+	})
+	ctx := context.Background()
+
+	payment, err := provider.Airtime(ctx, AirtimeRequest{Network: NineMob, Phone: "08011111111", Amount: vt.NewMoney(500)})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccessful, payment.Status)
+	assert.Equal(t, "17000", payment.ProviderReference)
+	assert.Equal(t, vt.NewMoney(500), payment.Amount)
+	assert.NotEmpty(t, payment.Reference)
+	assert.Equal(t, "etisalat", sent[0]["serviceID"])
+
+	_, err = provider.Data(ctx, DataRequest{Network: MTN, Phone: "08011111111", Plan: "mtn-10mb-100"})
+	require.NoError(t, err)
+	assert.Equal(t, "mtn-data", sent[1]["serviceID"])
+	assert.Equal(t, "mtn-10mb-100", sent[1]["variation_code"])
+	assert.Equal(t, "08011111111", sent[1]["billersCode"])
+
+	_, err = provider.Airtime(ctx, AirtimeRequest{Network: "ntel", Phone: "08011111111", Amount: vt.NewMoney(100)})
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestVTPassElectricityAndTV(t *testing.T) {
+	var sent []map[string]interface{}
+	provider := newTestVTPass(t, func(endpoint string, body map[string]interface{}) string {
+		switch endpoint {
+		case "merchant-verify":
+			if body["billersCode"] == "0000000000" {
+				return `{"code":"000","content":{"error":"This Smartcard number is invalid","WrongBillersCode":true}}`
+			}
+			return `{"code":"000","content":{"Customer_Name":"ADA OBI","Current_Bouquet":"DStv Compact","Renewal_Amount":"10,500.00","Due_Date":"2024-06-30"}}`
+		case "pay":
+			sent = append(sent, body)
+			return `{"code":"000","content":{"transactions":{"status":"delivered"}},"purchased_code":"Token : 12345678901234567890","units":"30 kWh"}`
+		}
+		return `{"code":"011"}`
+	})
+	ctx := context.Background()
+
+	payment, err := provider.Electricity(ctx, ElectricityRequest{
+		Biller:      "ikeja-electric",
+		MeterNumber: "1111111111111",
+		MeterType:   Prepaid,
+		Amount:      vt.NewMoney(1000),
+		Phone:       "08011111111",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1234-5678-9012-3456-7890", payment.Token)
+	assert.Equal(t, "30 kWh", payment.Units)
+	assert.Equal(t, "prepaid", sent[0]["variation_code"])
+
+	customer, err := provider.Verify(ctx, VerifyRequest{Biller: DSTV, CustomerID: "7027914329"})
+	require.NoError(t, err)
+	assert.Equal(t, "ADA OBI", customer.Name)
+	assert.Equal(t, "DStv Compact", customer.Plan)
+	assert.Equal(t, vt.NewMoney(10500), customer.RenewalAmount)
+
+	_, err = provider.Verify(ctx, VerifyRequest{Biller: DSTV, CustomerID: "0000000000"})
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+
+	_, err = provider.TV(ctx, TVRequest{Biller: DSTV, SmartcardNumber: "7027914329", Amount: vt.NewMoney(10500), Phone: "08011111111"})
+	require.NoError(t, err)
+	assert.Equal(t, "renew", sent[1]["subscription_type"])
+
+	_, err = provider.TV(ctx, TVRequest{Biller: DSTV, SmartcardNumber: "7027914329", Plan: "dstv-padi", Phone: "08011111111"})
+	require.NoError(t, err)
+	assert.Equal(t, "change", sent[2]["subscription_type"])
+	assert.Equal(t, "dstv-padi", sent[2]["variation_code"])
+}
+
+func TestVTPassRequeryAndBalance(t *testing.T) {
+	provider := newTestVTPass(t, func(endpoint string, body map[string]interface{}) string {
+		switch endpoint {
+		case "balance":
+			return `{"code":"000","contents":{"balance":"2,500.50"}}`
+		case "requery":
+			if body["request_id"] == "missing" {
+				return `{"code":"015","response_description":"INVALID REQUEST ID"}`
+			}
+			return `{"code":"099","content":{"transactions":{"status":"pending","transactionId":"17001"}}}`
+		case "pay":
+			return `{"code":"018","response_description":"LOW WALLET BALANCE"}`
+		}
+		return `{}`
+	})
+	ctx := context.Background()
+
+	balance, err := provider.Balance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, vt.NewMoney(2500.50), balance)
+
+	payment, err := provider.Requery(ctx, "202401011200abc")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, payment.Status)
+	assert.Equal(t, "17001", payment.ProviderReference)
+
+	_, err = provider.Requery(ctx, "missing")
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+
+	_, err = provider.Airtime(ctx, AirtimeRequest{Network: MTN, Phone: "08011111111", Amount: vt.NewMoney(100)})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.True(t, vt.IsLowBalance(err))
+}
@@ -0,0 +1,254 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PurchaseItem is a single purchase within a bulk run.
+type PurchaseItem struct {
+	// Reference identifies the item to the caller, e.g. an employee ID.
+	Reference     string
+	ServiceID     string
+	BillersCode   string
+	VariationCode string
+	Amount        float64
+	Phone         string
+}
+
+// BulkOptions configures BulkPurchase.
+type BulkOptions struct {
+	// Workers is the number of purchases sent concurrently. Defaults to 4.
+	Workers int
+	// RequeryInterval is the delay between requery attempts for pending
+	// items. Defaults to 5 seconds.
+	RequeryInterval time.Duration
+	// RequeryAttempts is the number of times a pending item is requeried
+	// before it is reported as pending. Defaults to 3.
+	RequeryAttempts int
+}
+
+type BulkStatus string
+
+const (
+	BulkSucceeded BulkStatus = "succeeded"
+	BulkFailed    BulkStatus = "failed"
+	BulkPending   BulkStatus = "pending"
+	// BulkSkipped marks items that were never sent because the run was
+	// stopped by a low balance or a cancelled context, or because live
+	// purchases are locked.
+	BulkSkipped BulkStatus = "skipped"
+)
+
+// BulkResult is the outcome of one PurchaseItem.
+type BulkResult struct {
+	Index     int
+	Item      PurchaseItem
+	RequestID string
+	Status    BulkStatus
+	Response  *PayResponse
+	Err       error
+}
+
+// BulkSummary totals the results of a bulk run.
+type BulkSummary struct {
+	Succeeded  int
+	Failed     int
+	Pending    int
+	Skipped    int
+	TotalSpent float64
+	// LowBalance is set when the run stopped early on a 018 response.
+	LowBalance bool
+}
+
+// BulkJob is a running bulk purchase.
+type BulkJob struct {
+	results chan BulkResult
+	done    chan struct{}
+	summary BulkSummary
+}
+
+// Results streams per-item results as they complete. The channel is
+// buffered for every item and closed when the run finishes.
+func (j *BulkJob) Results() <-chan BulkResult {
+	return j.results
+}
+
+// Wait blocks until the run finishes and returns its summary.
+func (j *BulkJob) Wait() BulkSummary {
+	<-j.done
+	return j.summary
+}
+
+// BulkPurchase sends items over a bounded worker pool. Each item gets its
+// own request ID. The run stops sending new items once VTPass reports a
+// low wallet balance, and pending items are resolved through requery.
+// While live purchases are locked every item is skipped with the
+// LiveGuard's error.
+func (s *VTService) BulkPurchase(ctx context.Context, items []PurchaseItem, opts BulkOptions) *BulkJob {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.RequeryInterval <= 0 {
+		opts.RequeryInterval = 5 * time.Second
+	}
+	if opts.RequeryAttempts <= 0 {
+		opts.RequeryAttempts = 3
+	}
+
+	job := &BulkJob{
+		results: make(chan BulkResult, len(items)),
+		done:    make(chan struct{}),
+	}
+
+	var (
+		stopped atomic.Bool
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+	)
+	locked := s.checkLive()
+
+	record := func(result BulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch result.Status {
+		case BulkSucceeded:
+			job.summary.Succeeded++
+			job.summary.TotalSpent += amountSpent(result)
+		case BulkFailed:
+			job.summary.Failed++
+		case BulkPending:
+			job.summary.Pending++
+		case BulkSkipped:
+			job.summary.Skipped++
+		}
+		job.results <- result
+	}
+
+	queue := make(chan int)
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if locked != nil {
+					record(BulkResult{Index: i, Item: items[i], Status: BulkSkipped, Err: locked})
+					continue
+				}
+				if stopped.Load() || ctx.Err() != nil {
+					record(BulkResult{Index: i, Item: items[i], Status: BulkSkipped, Err: ctx.Err()})
+					continue
+				}
+
+				result := s.purchaseItem(ctx, items[i], opts)
+				result.Index = i
+				if IsLowBalance(result.Err) {
+					stopped.Store(true)
+					mu.Lock()
+					job.summary.LowBalance = true
+					mu.Unlock()
+				}
+				record(result)
+			}
+		}()
+	}
+
+	go func() {
+		for i := range items {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+		close(job.results)
+		close(job.done)
+	}()
+
+	return job
+}
+
+// purchaseItem pays for a single item and requeries it while pending or
+// while the outcome is unknown.
+func (s *VTService) purchaseItem(ctx context.Context, item PurchaseItem, opts BulkOptions) BulkResult {
+	result := BulkResult{
+		Item:      item,
+		RequestID: s.GenerateRequestID(),
+	}
+
+	resp, err := s.Purchase(ctx, PurchaseRequest{
+		RequestID:     result.RequestID,
+		ServiceID:     item.ServiceID,
+		BillersCode:   item.BillersCode,
+		VariationCode: item.VariationCode,
+		Amount:        item.Amount,
+		Phone:         item.Phone,
+	})
+
+	var errorResponse ErrorResponse
+	switch {
+	case errors.Is(err, ErrLiveLocked):
+		// Locked after the run started; nothing was sent.
+		result.Status = BulkSkipped
+		result.Err = err
+		return result
+	case err == nil:
+		result.Response = resp
+		result.Status = purchaseStatus(resp.Code, resp.Content.Transactions.Status)
+	case errors.As(err, &errorResponse) && !IsDuplicateRequestID(err):
+		// VTPass refused the purchase, so nothing was charged.
+		result.Status = BulkFailed
+		result.Err = err
+		return result
+	default:
+		// HTTP and transport errors may come after the request reached
+		// VTPass, so the outcome is unknown until requeried.
+		result.Status = BulkPending
+		result.Err = err
+	}
+
+	for attempt := 0; result.Status == BulkPending && attempt < opts.RequeryAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(opts.RequeryInterval):
+		}
+
+		txn, err := s.QueryTransaction(ctx, result.RequestID)
+		if err != nil {
+			continue
+		}
+		result.Status = purchaseStatus(txn.Code, txn.Content.Transactions.Status)
+		if result.Status != BulkPending {
+			result.Err = nil
+		}
+		if result.Response == nil {
+			result.Response = &PayResponse{Code: txn.Code, RequestID: txn.RequestID}
+		}
+		result.Response.Content.Transactions = txn.Content.Transactions
+	}
+
+	return result
+}
+
+// purchaseStatus maps a VTPass response code and transaction status to a
+// bulk outcome. Responses that settle nothing, such as ones without a code
+// or with a code VTPass does not document, are pending until requeried.
+func purchaseStatus(code string, status TransactionStatus) BulkStatus {
+	switch transactionStatus(code, status) {
+	case StatusDelivered:
+		return BulkSucceeded
+	case StatusFailed, StatusReversed:
+		return BulkFailed
+	}
+	return BulkPending
+}
+
+func amountSpent(result BulkResult) float64 {
+	if result.Response != nil && result.Response.Content.Transactions.TotalAmount > 0 {
+		return result.Response.Content.Transactions.TotalAmount.Float64()
+	}
+	return result.Item.Amount
+}
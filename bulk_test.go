@@ -0,0 +1,146 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonResponse(status int, body interface{}) *http.Response {
+	b, _ := json.Marshal(body)
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(status)
+	rec.Write(b)
+	return rec.Result()
+}
+
+func TestBulkPurchase(t *testing.T) {
+	requeried := map[string]bool{}
+
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		if path == "requery" {
+			id := payload.(map[string]interface{})["request_id"].(string)
+			requeried[id] = true
+			var txn TransactionResponse
+			txn.Code = TRANSACTION_SUCCESSFUL
+			txn.Content.Transactions.Status = "delivered"
+			txn.Content.Transactions.TotalAmount = 200
+			return jsonResponse(http.StatusOK, txn), nil
+		}
+
+		req := payload.(PurchaseRequest)
+		var resp PayResponse
+		switch req.Phone {
+		case "08000000001":
+			resp.Code = TRANSACTION_SUCCESSFUL
+			resp.Content.Transactions.Status = "delivered"
+			resp.Content.Transactions.TotalAmount = 98
+		case "08000000002":
+			resp.Code = TRANSACTION_PROCESSING
+			resp.Content.Transactions.Status = "pending"
+		case "08000000003":
+			resp.Code = LOW_WALLET_BALANCE
+		}
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	service := &VTService{client: mockClient}
+
+	items := []PurchaseItem{
+		{Reference: "a", ServiceID: "mtn", Amount: 100, Phone: "08000000001"},
+		{Reference: "b", ServiceID: "mtn", Amount: 200, Phone: "08000000002"},
+		{Reference: "c", ServiceID: "mtn", Amount: 300, Phone: "08000000003"},
+		{Reference: "d", ServiceID: "mtn", Amount: 400, Phone: "08000000001"},
+	}
+
+	job := service.BulkPurchase(context.Background(), items, BulkOptions{
+		Workers:         1,
+		RequeryInterval: time.Millisecond,
+	})
+
+	statuses := map[string]BulkStatus{}
+	for result := range job.Results() {
+		statuses[result.Item.Reference] = result.Status
+	}
+	summary := job.Wait()
+
+	assert.Equal(t, map[string]BulkStatus{
+		"a": BulkSucceeded,
+		"b": BulkSucceeded,
+		"c": BulkFailed,
+		"d": BulkSkipped,
+	}, statuses)
+	assert.Len(t, requeried, 1)
+	assert.Equal(t, BulkSummary{Succeeded: 2, Failed: 1, Skipped: 1, TotalSpent: 298, LowBalance: true}, summary)
+}
+
+func TestBulkPurchaseRequeriesUnknownOutcomes(t *testing.T) {
+	requeried := map[string]int{}
+	phones := map[string]string{}
+
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		if path == "requery" {
+			id := payload.(map[string]interface{})["request_id"].(string)
+			requeried[id]++
+			var txn TransactionResponse
+			if phones[id] == "08000000003" {
+				// VTPass cannot see the purchase yet.
+				txn.Code = INVALID_REQUEST_ID
+				return jsonResponse(http.StatusOK, txn), nil
+			}
+			txn.Code = TRANSACTION_SUCCESSFUL
+			txn.Content.Transactions.Status = "delivered"
+			return jsonResponse(http.StatusOK, txn), nil
+		}
+
+		req := payload.(PurchaseRequest)
+		phones[req.RequestID] = req.Phone
+		switch req.Phone {
+		case "08000000001":
+			return jsonResponse(http.StatusOK, map[string]string{}), nil
+		case "08000000002":
+			return nil, errors.New("connection reset")
+		}
+		return jsonResponse(http.StatusBadGateway, map[string]string{}), nil
+	})
+
+	service := &VTService{client: mockClient}
+
+	items := []PurchaseItem{
+		{Reference: "codeless", ServiceID: "mtn", Amount: 100, Phone: "08000000001"},
+		{Reference: "transport", ServiceID: "mtn", Amount: 100, Phone: "08000000002"},
+		{Reference: "gateway", ServiceID: "mtn", Amount: 100, Phone: "08000000003"},
+	}
+
+	job := service.BulkPurchase(context.Background(), items, BulkOptions{
+		Workers:         1,
+		RequeryInterval: time.Millisecond,
+		RequeryAttempts: 2,
+	})
+
+	results := map[string]BulkResult{}
+	for result := range job.Results() {
+		results[result.Item.Reference] = result
+	}
+	summary := job.Wait()
+
+	assert.Equal(t, BulkSucceeded, results["codeless"].Status)
+	assert.Equal(t, BulkSucceeded, results["transport"].Status)
+	assert.NoError(t, results["transport"].Err)
+	assert.Equal(t, BulkPending, results["gateway"].Status)
+	assert.Error(t, results["gateway"].Err)
+	assert.Equal(t, 2, requeried[results["gateway"].RequestID])
+	assert.Equal(t, 2, summary.Succeeded)
+	assert.Equal(t, 1, summary.Pending)
+	assert.Zero(t, summary.Failed)
+}
@@ -0,0 +1,150 @@
+// Command vtpass-gateway serves the REST gateway of package server and,
+// when GATEWAY_GRPC_ADDR is set, the gRPC API of package grpcserver.
+//
+// Configuration is read from the environment (and a .env file if present):
+//
+//	API_KEY, PUBLIC_KEY, SECRET_KEY  VTPass credentials, re-read on every request
+//	ENVIRONMENT                      sandbox (default) or live; other values are rejected
+//	GATEWAY_ADDR                     listen address, default :8080
+//	GATEWAY_GRPC_ADDR                gRPC listen address; gRPC is off when unset
+//	GATEWAY_API_KEYS                 comma-separated keys accepted from callers
+//	GATEWAY_WEBHOOK_TOKEN            token required on the webhook URL; the webhook is off when unset
+//	GATEWAY_DB                       SQLite file for transaction history; in memory when unset
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+	"github.com/CeoFred/vtpass-go/grpcserver"
+	vtpassv1 "github.com/CeoFred/vtpass-go/proto/vtpass/v1"
+	"github.com/CeoFred/vtpass-go/server"
+	"github.com/CeoFred/vtpass-go/sqlstore"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("loading .env: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := openStore(ctx, os.Getenv("GATEWAY_DB"))
+	if err != nil {
+		log.Fatalf("opening transaction store: %v", err)
+	}
+
+	environment := vt.EnvironmentSandbox
+	if value := os.Getenv("ENVIRONMENT"); value != "" {
+		if environment, err = vt.ParseEnvironment(value); err != nil {
+			log.Fatalf("ENVIRONMENT: %v", err)
+		}
+	}
+
+	service, err := vt.NewVTServiceStrict("", "", "", environment,
+		vt.WithCredentialsProvider(vt.NewEnvCredentials()),
+		vt.WithTransactionStore(store))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	apiKeys := splitList(os.Getenv("GATEWAY_API_KEYS"))
+	webhookToken := os.Getenv("GATEWAY_WEBHOOK_TOKEN")
+	if webhookToken == "" {
+		log.Printf("GATEWAY_WEBHOOK_TOKEN is not set; the VTPass webhook is disabled")
+	}
+	grpcService := grpcserver.New(service, grpcserver.Options{})
+
+	handler, err := server.New(server.Config{
+		Service:      service,
+		APIKeys:      apiKeys,
+		WebhookToken: webhookToken,
+		OnTransactionUpdate: func(ctx context.Context, record *vt.TransactionRecord) {
+			grpcService.Notify(record.RequestID)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if grpcAddr := os.Getenv("GATEWAY_GRPC_ADDR"); grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpcServer := grpc.NewServer(grpcserver.APIKeyAuth(apiKeys...)...)
+		vtpassv1.RegisterVTPassServiceServer(grpcServer, grpcService)
+
+		go func() {
+			<-ctx.Done()
+			grpcServer.GracefulStop()
+		}()
+		go func() {
+			log.Printf("vtpass gateway serving gRPC on %s", grpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	addr := os.Getenv("GATEWAY_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("vtpass gateway listening on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func openStore(ctx context.Context, path string) (vt.TransactionStore, error) {
+	if path == "" {
+		return vt.NewMemoryTransactionStore(), nil
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	store := sqlstore.New(db, sqlstore.SQLite)
+	if err := store.Migrate(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
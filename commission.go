@@ -0,0 +1,114 @@
+package vtupass_go
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// CommissionAmount returns the commission VTPass earned the merchant on
+// the transaction.
+func (t Transaction) CommissionAmount() Money {
+	return NewMoney(t.Commission.Float64())
+}
+
+// DiscountAmount returns the discount VTPass applied to the transaction.
+func (t Transaction) DiscountAmount() Money {
+	return NewMoney(t.Discount.Float64())
+}
+
+// NetDebit returns the amount taken from the wallet. VTPass reports it as
+// total_amount, net of commission; it is derived from the other amounts
+// when missing.
+func (t Transaction) NetDebit() Money {
+	if t.TotalAmount > 0 {
+		return NewMoney(t.TotalAmount.Float64())
+	}
+	return NewMoney(t.Amount.Float64()+t.ConvenienceFee.Float64()) - t.CommissionAmount() - t.DiscountAmount()
+}
+
+// Commission returns the commission earned on the purchase.
+func (r PayResponse) Commission() Money {
+	return r.Content.Transactions.CommissionAmount()
+}
+
+// Discount returns the discount applied to the purchase.
+func (r PayResponse) Discount() Money {
+	return r.Content.Transactions.DiscountAmount()
+}
+
+// NetDebit returns the amount the purchase took from the wallet.
+func (r PayResponse) NetDebit() Money {
+	return r.Content.Transactions.NetDebit()
+}
+
+// CommissionSummary totals the delivered purchases of one service on one
+// day.
+type CommissionSummary struct {
+	// Date is the day in Africa/Lagos time, formatted as 2006-01-02.
+	Date       string `json:"date"`
+	ServiceID  string `json:"service_id"`
+	Count      int    `json:"count"`
+	Amount     Money  `json:"amount"`
+	Commission Money  `json:"commission"`
+	Discount   Money  `json:"discount"`
+	NetDebit   Money  `json:"net_debit"`
+}
+
+// CommissionByDay sums commission per service per day over the delivered
+// purchases in the transaction store that match filter, for reconciling
+// against VTPass earnings statements. Results are ordered by date, then
+// service. The filter's Status, Cursor and Limit are ignored.
+func (s *VTService) CommissionByDay(ctx context.Context, filter TransactionFilter) ([]CommissionSummary, error) {
+	if s.store == nil {
+		return nil, ErrNoTransactionStore
+	}
+
+	type key struct{ date, serviceID string }
+	totals := make(map[key]*CommissionSummary)
+
+	filter.Status = StatusDelivered
+	filter.Cursor = ""
+	filter.Limit = exportPageLimit
+	for {
+		page, err := s.store.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page.Transactions {
+			k := key{record.CreatedAt.In(lagos).Format(time.DateOnly), record.ServiceID}
+			total, ok := totals[k]
+			if !ok {
+				total = &CommissionSummary{Date: k.date, ServiceID: k.serviceID}
+				totals[k] = total
+			}
+
+			txn := record.Transaction
+			amount := NewMoney(txn.Amount.Float64())
+			if amount == 0 {
+				amount = NewMoney(record.Amount)
+			}
+			total.Count++
+			total.Amount += amount
+			total.Commission += txn.CommissionAmount()
+			total.Discount += txn.DiscountAmount()
+			total.NetDebit += txn.NetDebit()
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	summaries := make([]CommissionSummary, 0, len(totals))
+	for _, total := range totals {
+		summaries = append(summaries, *total)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Date != summaries[j].Date {
+			return summaries[i].Date < summaries[j].Date
+		}
+		return summaries[i].ServiceID < summaries[j].ServiceID
+	})
+	return summaries, nil
+}
@@ -0,0 +1,65 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayResponseCommission(t *testing.T) {
+	var resp PayResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"000","content":{"transactions":{
+		"status":"delivered","amount":"100","convenience_fee":0,"total_amount":97,"commission":3,"discount":null}}}`), &resp))
+
+	assert.Equal(t, NewMoney(3), resp.Commission())
+	assert.Zero(t, resp.Discount())
+	assert.Equal(t, NewMoney(97), resp.NetDebit())
+
+	derived := Transaction{Amount: 1000, ConvenienceFee: 50, Commission: 15, Discount: 5}
+	assert.Equal(t, NewMoney(1030), derived.NetDebit())
+}
+
+func TestCommissionByDay(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	service := &VTService{store: store}
+	ctx := context.Background()
+
+	day1 := time.Date(2024, 3, 1, 9, 0, 0, 0, lagos)
+	// 23:30 UTC on 1 March is already 2 March in Lagos.
+	day2 := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	records := []TransactionRecord{
+		{RequestID: "a", ServiceID: "mtn", Status: StatusDelivered, CreatedAt: day1,
+			Transaction: Transaction{Amount: 100, TotalAmount: 97, Commission: 3}},
+		{RequestID: "b", ServiceID: "mtn", Status: StatusDelivered, CreatedAt: day1.Add(time.Hour),
+			Transaction: Transaction{Amount: 200, TotalAmount: 194, Commission: 6}},
+		{RequestID: "c", ServiceID: "dstv", Status: StatusDelivered, CreatedAt: day1,
+			Transaction: Transaction{Amount: 5000, TotalAmount: 4925, Commission: 75}},
+		{RequestID: "d", ServiceID: "mtn", Status: StatusFailed, CreatedAt: day1,
+			Transaction: Transaction{Amount: 100, Commission: 3}},
+		{RequestID: "e", ServiceID: "mtn", Status: StatusDelivered, CreatedAt: day2,
+			Transaction: Transaction{Amount: 50, TotalAmount: 48.5, Commission: 1.5}},
+	}
+	for _, record := range records {
+		require.NoError(t, store.Save(ctx, record))
+	}
+
+	summaries, err := service.CommissionByDay(ctx, TransactionFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []CommissionSummary{
+		{Date: "2024-03-01", ServiceID: "dstv", Count: 1, Amount: NewMoney(5000), Commission: NewMoney(75), NetDebit: NewMoney(4925)},
+		{Date: "2024-03-01", ServiceID: "mtn", Count: 2, Amount: NewMoney(300), Commission: NewMoney(9), NetDebit: NewMoney(291)},
+		{Date: "2024-03-02", ServiceID: "mtn", Count: 1, Amount: NewMoney(50), Commission: NewMoney(1.5), NetDebit: NewMoney(48.5)},
+	}, summaries)
+
+	summaries, err = service.CommissionByDay(ctx, TransactionFilter{ServiceID: "dstv"})
+	require.NoError(t, err)
+	assert.Len(t, summaries, 1)
+
+	_, err = (&VTService{}).CommissionByDay(ctx, TransactionFilter{})
+	assert.ErrorIs(t, err, ErrNoTransactionStore)
+}
@@ -9,6 +9,26 @@ const INVALID_ARGUMENTS = "011"
 const PRODUCT_DOES_NOT_EXIST = "012"
 const BILLER_NOT_REACHABLE_AT_THIS_POINT = "030"
 const INVALID_CREDENTIALS = "087"
+const TRANSACTION_SUCCESSFUL = "000"
+const TRANSACTION_PROCESSING = "099"
+const TRANSACTION_FAILED = "016"
+const LOW_WALLET_BALANCE = "018"
+const INVALID_REQUEST_ID = "015"
+const VARIATION_CODE_DOES_NOT_EXIST = "010"
+const BELOW_MINIMUM_AMOUNT = "013"
+const REQUEST_ID_ALREADY_EXISTS = "014"
+const ABOVE_MAXIMUM_AMOUNT = "017"
+const LIKELY_DUPLICATE_TRANSACTION = "019"
+const ACCOUNT_LOCKED = "021"
+const ACCOUNT_SUSPENDED = "022"
+const API_ACCESS_NOT_ENABLED = "023"
+const ACCOUNT_INACTIVE = "024"
+const RECIPIENT_BANK_INVALID = "025"
+const RECIPIENT_ACCOUNT_NOT_VERIFIED = "026"
+const IP_NOT_WHITELISTED = "027"
+const PRODUCT_NOT_WHITELISTED = "028"
+const IMPROPER_REQUEST_ID = "085"
+const TRANSACTION_NOT_PROCESSED = "091"
 
 
 const (
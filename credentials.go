@@ -0,0 +1,192 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuthMode selects how requests are authenticated.
+type AuthMode string
+
+const (
+	// AuthModeKeys sends api-key with public-key on GET requests and with
+	// secret-key on POST requests. It is the default.
+	AuthModeKeys AuthMode = ""
+	// AuthModeBasic sends the account username and password as HTTP Basic
+	// authentication, for older accounts without API keys.
+	AuthModeBasic AuthMode = "basic"
+)
+
+// Credentials are the keys used to authenticate with VTPass.
+type Credentials struct {
+	Mode      AuthMode `json:"mode"`
+	APIKey    string   `json:"api_key"`
+	PublicKey string   `json:"public_key"`
+	SecretKey string   `json:"secret_key"`
+	Username  string   `json:"username"`
+	Password  string   `json:"password"`
+}
+
+// BasicAuth returns credentials for an account that authenticates with a
+// username and password.
+func BasicAuth(username, password string) StaticCredentials {
+	return StaticCredentials{Mode: AuthModeBasic, Username: username, Password: password}
+}
+
+// headers returns the authentication headers for a request. The secret key
+// is only sent on requests that change state.
+func (c Credentials) headers(method string) map[string]string {
+	if c.Mode == AuthModeBasic {
+		token := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+		return map[string]string{"Authorization": "Basic " + token}
+	}
+
+	if method == http.MethodGet {
+		return map[string]string{
+			"api-key":    c.APIKey,
+			"public-key": c.PublicKey,
+		}
+	}
+	return map[string]string{
+		"api-key":    c.APIKey,
+		"secret-key": c.SecretKey,
+	}
+}
+
+// CredentialsProvider supplies credentials for every request. Refresh is
+// called once when VTPass rejects the current credentials with 087, after
+// which the request is retried if the credentials changed.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+	Refresh(ctx context.Context) error
+}
+
+// StaticCredentials never change. NewVTService uses them by default.
+type StaticCredentials Credentials
+
+func (c StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(c), nil
+}
+
+func (c StaticCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// CredentialsFunc adapts a callback, e.g. a secrets manager lookup, to a
+// CredentialsProvider. It is called on every request.
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+func (f CredentialsFunc) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// EnvCredentials reads credentials from environment variables on every
+// request, so updating the environment rotates the keys.
+type EnvCredentials struct {
+	APIKeyVar    string
+	PublicKeyVar string
+	SecretKeyVar string
+}
+
+// NewEnvCredentials reads API_KEY, PUBLIC_KEY and SECRET_KEY.
+func NewEnvCredentials() *EnvCredentials {
+	return &EnvCredentials{
+		APIKeyVar:    "API_KEY",
+		PublicKeyVar: "PUBLIC_KEY",
+		SecretKeyVar: "SECRET_KEY",
+	}
+}
+
+func (e *EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	creds := Credentials{
+		APIKey:    os.Getenv(e.APIKeyVar),
+		PublicKey: os.Getenv(e.PublicKeyVar),
+		SecretKey: os.Getenv(e.SecretKeyVar),
+	}
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("environment variable %s is not set", e.APIKeyVar)
+	}
+	return creds, nil
+}
+
+func (e *EnvCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// FileCredentials loads credentials from a JSON file with api_key,
+// public_key and secret_key fields. The file is re-read whenever its
+// modification time changes, checked at most once per CheckInterval.
+type FileCredentials struct {
+	Path          string
+	CheckInterval time.Duration
+
+	mu      sync.Mutex
+	creds   Credentials
+	modTime time.Time
+	checked time.Time
+}
+
+// NewFileCredentials loads credentials from path.
+func NewFileCredentials(path string) (*FileCredentials, error) {
+	f := &FileCredentials{Path: path, CheckInterval: 5 * time.Second}
+	if err := f.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checked) >= f.CheckInterval {
+		f.checked = time.Now()
+		info, err := os.Stat(f.Path)
+		if err == nil && !info.ModTime().Equal(f.modTime) {
+			if err := f.load(); err != nil {
+				return Credentials{}, err
+			}
+		}
+	}
+	return f.creds, nil
+}
+
+// Refresh re-reads the file regardless of its modification time.
+func (f *FileCredentials) Refresh(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+// load must be called with f.mu held.
+func (f *FileCredentials) load() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return fmt.Errorf("parsing credentials file %s: %w", f.Path, err)
+	}
+
+	f.creds = creds
+	f.modTime = info.ModTime()
+	f.checked = time.Now()
+	return nil
+}
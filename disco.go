@@ -0,0 +1,184 @@
+package vtupass_go
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Disco is the VTPass serviceID of an electricity distribution company.
+type Disco string
+
+const (
+	DiscoIkeja        Disco = "ikeja-electric"
+	DiscoEko          Disco = "eko-electric"
+	DiscoAbuja        Disco = "abuja-electric"
+	DiscoKano         Disco = "kano-electric"
+	DiscoPortHarcourt Disco = "portharcourt-electric"
+	DiscoJos          Disco = "jos-electric"
+	DiscoIbadan       Disco = "ibadan-electric"
+	DiscoKaduna       Disco = "kaduna-electric"
+	DiscoEnugu        Disco = "enugu-electric"
+	DiscoBenin        Disco = "benin-electric"
+	DiscoAba          Disco = "aba-electric"
+	DiscoYola         Disco = "yola-electric"
+)
+
+// MeterType is the variation code used for electricity purchases.
+type MeterType string
+
+const (
+	MeterTypePrepaid  MeterType = "prepaid"
+	MeterTypePostpaid MeterType = "postpaid"
+)
+
+var (
+	ErrUnknownDisco       = errors.New("unknown disco")
+	ErrInvalidMeterType   = errors.New("meter type not supported by disco")
+	ErrInvalidMeterNumber = errors.New("invalid meter number")
+)
+
+// LengthRule bounds the number of digits in a meter or account number.
+type LengthRule struct {
+	Min int
+	Max int
+}
+
+// DiscoInfo describes a disco.
+type DiscoInfo struct {
+	Disco     Disco
+	Name      string
+	ShortName string
+	States    []string
+	// MeterNumberLengths holds the accepted number of digits per meter
+	// type. Prepaid entries are meter numbers; postpaid entries are
+	// account numbers.
+	MeterNumberLengths map[MeterType]LengthRule
+}
+
+// SupportsMeterType reports whether the disco sells to meterType.
+func (i DiscoInfo) SupportsMeterType(meterType MeterType) bool {
+	_, ok := i.MeterNumberLengths[meterType]
+	return ok
+}
+
+var standardLengths = map[MeterType]LengthRule{
+	MeterTypePrepaid:  {Min: 11, Max: 13},
+	MeterTypePostpaid: {Min: 10, Max: 13},
+}
+
+var longAccountLengths = map[MeterType]LengthRule{
+	MeterTypePrepaid:  {Min: 11, Max: 13},
+	MeterTypePostpaid: {Min: 10, Max: 16},
+}
+
+var discos = map[Disco]DiscoInfo{
+	DiscoIkeja: {
+		Disco: DiscoIkeja, Name: "Ikeja Electric", ShortName: "IKEDC",
+		States:             []string{"Lagos"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoEko: {
+		Disco: DiscoEko, Name: "Eko Electricity Distribution Company", ShortName: "EKEDC",
+		States:             []string{"Lagos", "Ogun"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoAbuja: {
+		Disco: DiscoAbuja, Name: "Abuja Electricity Distribution Company", ShortName: "AEDC",
+		States:             []string{"FCT", "Kogi", "Nasarawa", "Niger"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoKano: {
+		Disco: DiscoKano, Name: "Kano Electricity Distribution Company", ShortName: "KEDCO",
+		States:             []string{"Kano", "Jigawa", "Katsina"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoPortHarcourt: {
+		Disco: DiscoPortHarcourt, Name: "Port Harcourt Electricity Distribution Company", ShortName: "PHED",
+		States:             []string{"Rivers", "Bayelsa", "Cross River", "Akwa Ibom"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoJos: {
+		Disco: DiscoJos, Name: "Jos Electricity Distribution Company", ShortName: "JED",
+		States:             []string{"Plateau", "Bauchi", "Benue", "Gombe"},
+		MeterNumberLengths: longAccountLengths,
+	},
+	DiscoIbadan: {
+		Disco: DiscoIbadan, Name: "Ibadan Electricity Distribution Company", ShortName: "IBEDC",
+		States:             []string{"Oyo", "Ogun", "Osun", "Kwara"},
+		MeterNumberLengths: longAccountLengths,
+	},
+	DiscoKaduna: {
+		Disco: DiscoKaduna, Name: "Kaduna Electric", ShortName: "KAEDCO",
+		States:             []string{"Kaduna", "Kebbi", "Sokoto", "Zamfara"},
+		MeterNumberLengths: longAccountLengths,
+	},
+	DiscoEnugu: {
+		Disco: DiscoEnugu, Name: "Enugu Electricity Distribution Company", ShortName: "EEDC",
+		States:             []string{"Enugu", "Abia", "Anambra", "Ebonyi", "Imo"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoBenin: {
+		Disco: DiscoBenin, Name: "Benin Electricity Distribution Company", ShortName: "BEDC",
+		States:             []string{"Edo", "Delta", "Ondo", "Ekiti"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoAba: {
+		Disco: DiscoAba, Name: "Aba Power", ShortName: "APLE",
+		States:             []string{"Abia"},
+		MeterNumberLengths: standardLengths,
+	},
+	DiscoYola: {
+		Disco: DiscoYola, Name: "Yola Electricity Distribution Company", ShortName: "YEDC",
+		States:             []string{"Adamawa", "Borno", "Taraba", "Yobe"},
+		MeterNumberLengths: standardLengths,
+	},
+}
+
+// Discos returns every known disco ordered by serviceID.
+func Discos() []DiscoInfo {
+	list := make([]DiscoInfo, 0, len(discos))
+	for _, info := range discos {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Disco < list[j].Disco })
+	return list
+}
+
+// ParseDisco returns the Disco for a VTPass serviceID.
+func ParseDisco(serviceID string) (Disco, error) {
+	d := Disco(serviceID)
+	if _, ok := discos[d]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownDisco, serviceID)
+	}
+	return d, nil
+}
+
+// Info returns the disco's metadata.
+func (d Disco) Info() (DiscoInfo, bool) {
+	info, ok := discos[d]
+	return info, ok
+}
+
+// ValidateMeterNumber checks a meter or account number against the disco's
+// local rules before it is sent to VTPass.
+func (d Disco) ValidateMeterNumber(meterNumber string, meterType MeterType) error {
+	info, ok := discos[d]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownDisco, string(d))
+	}
+
+	rule, ok := info.MeterNumberLengths[meterType]
+	if !ok {
+		return fmt.Errorf("%w: %s does not support %q", ErrInvalidMeterType, info.ShortName, string(meterType))
+	}
+
+	if meterNumber == "" || digitsOnly(meterNumber) != meterNumber {
+		return fmt.Errorf("%w: %q must contain only digits", ErrInvalidMeterNumber, meterNumber)
+	}
+	if len(meterNumber) < rule.Min || len(meterNumber) > rule.Max {
+		return fmt.Errorf("%w: %s %s numbers have %d to %d digits, got %d",
+			ErrInvalidMeterNumber, info.ShortName, meterType, rule.Min, rule.Max, len(meterNumber))
+	}
+	return nil
+}
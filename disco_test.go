@@ -0,0 +1,54 @@
+package vtupass_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoRegistry(t *testing.T) {
+	assert.Len(t, Discos(), 12)
+
+	d, err := ParseDisco("portharcourt-electric")
+	assert.NoError(t, err)
+	assert.Equal(t, DiscoPortHarcourt, d)
+
+	_, err = ParseDisco("lagos-electric")
+	assert.ErrorIs(t, err, ErrUnknownDisco)
+
+	info, ok := DiscoEnugu.Info()
+	assert.True(t, ok)
+	assert.Contains(t, info.States, "Anambra")
+	assert.True(t, info.SupportsMeterType(MeterTypePostpaid))
+}
+
+func TestValidateMeterNumber(t *testing.T) {
+	testCases := []struct {
+		name      string
+		disco     Disco
+		number    string
+		meterType MeterType
+		err       error
+	}{
+		{"Sandbox Prepaid", DiscoEnugu, "1111111111111", MeterTypePrepaid, nil},
+		{"Sandbox Postpaid", DiscoEnugu, "1010101010101", MeterTypePostpaid, nil},
+		{"Eleven Digit Meter", DiscoIkeja, "45067198783", MeterTypePrepaid, nil},
+		{"Too Short", DiscoIkeja, "12345", MeterTypePrepaid, ErrInvalidMeterNumber},
+		{"Too Long", DiscoEko, "12345678901234", MeterTypePrepaid, ErrInvalidMeterNumber},
+		{"Letters", DiscoAbuja, "12345abc901", MeterTypePrepaid, ErrInvalidMeterNumber},
+		{"Empty", DiscoKano, "", MeterTypePrepaid, ErrInvalidMeterNumber},
+		{"Unknown Meter Type", DiscoJos, "1111111111111", MeterType("smart"), ErrInvalidMeterType},
+		{"Unknown Disco", Disco("lagos-electric"), "1111111111111", MeterTypePrepaid, ErrUnknownDisco},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.disco.ValidateMeterNumber(tc.number, tc.meterType)
+			if tc.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package vtupass_go
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownEnvironment is returned for environments other than
+	// sandbox and live.
+	ErrUnknownEnvironment = errors.New("unknown environment")
+	// ErrLiveLocked is returned by purchases against the live API while a
+	// LiveGuard is enabled and not unlocked.
+	ErrLiveLocked = errors.New("live purchases are locked")
+)
+
+// LiveUnlockEnv is the environment variable that unlocks live purchases
+// when a LiveGuard is enabled. It takes a boolean such as "true" or "1".
+const LiveUnlockEnv = "VTPASS_LIVE_UNLOCKED"
+
+// sandboxTestNumbers are the customer and phone numbers VTPass documents
+// for simulating outcomes in sandbox. Sent to live, they fail or reach a
+// stranger.
+var sandboxTestNumbers = map[string]string{
+	"1111111111111": "prepaid meter",
+	"1010101010101": "postpaid meter",
+	"1212121212":    "smartcard",
+	"08011111111":   "phone",
+	"201000000000":  "phone (pending)",
+	"500000000000":  "phone (unexpected response)",
+	"400000000000":  "phone (no response)",
+	"300000000000":  "phone (timeout)",
+}
+
+// ParseEnvironment returns the Environment named by value, ignoring case
+// and surrounding space. Unknown values return an error wrapping
+// ErrUnknownEnvironment.
+func ParseEnvironment(value string) (Environment, error) {
+	env := Environment(strings.ToLower(strings.TrimSpace(value)))
+	if err := env.Validate(); err != nil {
+		return "", err
+	}
+	return env, nil
+}
+
+// Validate returns an error wrapping ErrUnknownEnvironment unless e is
+// EnvironmentSandbox or EnvironmentLive.
+func (e Environment) Validate() error {
+	switch e {
+	case EnvironmentSandbox, EnvironmentLive:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownEnvironment, string(e))
+}
+
+// BaseURL returns the API URL of the environment.
+func (e Environment) BaseURL() (string, error) {
+	switch e {
+	case EnvironmentSandbox:
+		return SandboxBaseURL, nil
+	case EnvironmentLive:
+		return LiveEnviromentURL, nil
+	}
+	return "", e.Validate()
+}
+
+// NewVTServiceStrict returns a VTService for environment, or an error
+// wrapping ErrUnknownEnvironment if environment is neither sandbox nor
+// live. It is the constructor to use; NewVTService falls back to sandbox
+// instead.
+func NewVTServiceStrict(apiKey, publicKey, secretKey string, environment Environment, opts ...Option) (*VTService, error) {
+	if err := environment.Validate(); err != nil {
+		return nil, err
+	}
+	return NewVTService(apiKey, publicKey, secretKey, environment, opts...), nil
+}
+
+// WithLiveGuard blocks purchases against LiveEnviromentURL with
+// ErrLiveLocked until they are unlocked with WithLiveUnlocked or by
+// setting LiveUnlockEnv. It keeps tests and scripts pointed at live by
+// mistake from spending money.
+func WithLiveGuard() Option {
+	return func(s *VTService) {
+		s.liveGuard = true
+	}
+}
+
+// WithLiveUnlocked allows purchases against the live API when a LiveGuard
+// is enabled.
+func WithLiveUnlocked() Option {
+	return func(s *VTService) {
+		s.liveUnlocked = true
+	}
+}
+
+func (s *VTService) isLive() bool {
+	return s.baseURL == LiveEnviromentURL
+}
+
+// checkLive enforces the LiveGuard before a purchase.
+func (s *VTService) checkLive() error {
+	if !s.liveGuard || !s.isLive() || s.liveUnlocked {
+		return nil
+	}
+	if unlocked, _ := strconv.ParseBool(os.Getenv(LiveUnlockEnv)); unlocked {
+		return nil
+	}
+	return fmt.Errorf("%w: set %s=true or use WithLiveUnlocked", ErrLiveLocked, LiveUnlockEnv)
+}
+
+// warnSandboxNumbers logs a warning for each sandbox test number sent to
+// the live API.
+func (s *VTService) warnSandboxNumbers(numbers ...string) {
+	if !s.isLive() {
+		return
+	}
+	for _, number := range numbers {
+		if kind, ok := sandboxTestNumbers[number]; ok {
+			log.Printf("vtpass: sandbox test %s %s sent to the live API", kind, number)
+		}
+	}
+}
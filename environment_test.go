@@ -0,0 +1,140 @@
+package vtupass_go
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	env, err := ParseEnvironment(" Live ")
+	require.NoError(t, err)
+	assert.Equal(t, EnvironmentLive, env)
+
+	_, err = ParseEnvironment("production")
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+
+	_, err = NewVTServiceStrict("key", "pk", "sk", Environment("staging"))
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+
+	service, err := NewVTServiceStrict("key", "pk", "sk", EnvironmentLive)
+	require.NoError(t, err)
+	assert.Equal(t, LiveEnviromentURL, service.baseURL)
+}
+
+func TestLiveGuard(t *testing.T) {
+	var pays int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		pays++
+		return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`), nil
+	})
+	client := WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport}))
+	req := PurchaseRequest{ServiceID: "mtn", Amount: 100, Phone: "08031234567"}
+	ctx := context.Background()
+
+	sandbox := NewVTService("key", "pk", "sk", EnvironmentSandbox, client, WithLiveGuard())
+	_, err := sandbox.Purchase(ctx, req)
+	require.NoError(t, err)
+
+	live := NewVTService("key", "pk", "sk", EnvironmentLive, client, WithLiveGuard())
+	_, err = live.Purchase(ctx, req)
+	assert.ErrorIs(t, err, ErrLiveLocked)
+	assert.Equal(t, 1, pays)
+
+	t.Setenv(LiveUnlockEnv, "true")
+	_, err = live.Purchase(ctx, req)
+	require.NoError(t, err)
+	os.Unsetenv(LiveUnlockEnv)
+
+	unlocked := NewVTService("key", "pk", "sk", EnvironmentLive, client, WithLiveGuard(), WithLiveUnlocked())
+	_, err = unlocked.Purchase(ctx, req)
+	require.NoError(t, err)
+
+	unguarded := NewVTService("key", "pk", "sk", EnvironmentLive, client)
+	_, err = unguarded.Purchase(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 4, pays)
+}
+
+func TestSandboxNumbersInLiveWarn(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`), nil
+	})
+	client := WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport}))
+	req := PurchaseRequest{ServiceID: "ikeja-electric", BillersCode: "1111111111111", Amount: 100, Phone: "08011111111"}
+
+	_, err := NewVTService("key", "pk", "sk", EnvironmentSandbox, client).Purchase(context.Background(), req)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	_, err = NewVTService("key", "pk", "sk", EnvironmentLive, client).Purchase(context.Background(), req)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "sandbox test prepaid meter 1111111111111 sent to the live API")
+	assert.Contains(t, buf.String(), "sandbox test phone 08011111111")
+}
+
+func TestLiveGuardCoversEveryPayPath(t *testing.T) {
+	var calls int
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`), nil
+	})
+	client := WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport}))
+	store := NewMemoryTransactionStore()
+	live := NewVTService("key", "pk", "sk", EnvironmentLive, client, WithLiveGuard(), WithTransactionStore(store))
+	req := PurchaseRequest{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08031234567"}
+	ctx := context.Background()
+
+	job := live.BulkPurchase(ctx, []PurchaseItem{{ServiceID: "mtn", Amount: 100, Phone: "08031234567"}}, BulkOptions{})
+	for result := range job.Results() {
+		assert.Equal(t, BulkSkipped, result.Status)
+		assert.ErrorIs(t, result.Err, ErrLiveLocked)
+	}
+	assert.Equal(t, BulkSummary{Skipped: 1}, job.Wait())
+
+	outbox, err := NewOutbox(live, OutboxOptions{})
+	require.NoError(t, err)
+	_, err = outbox.Enqueue(ctx, req)
+	assert.ErrorIs(t, err, ErrLiveLocked)
+
+	// An intent recorded before the lock is not claimed.
+	require.NoError(t, store.Save(ctx, TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: StatusInitiated}))
+	_, err = outbox.Submit(ctx, "r1")
+	assert.ErrorIs(t, err, ErrLiveLocked)
+	record, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Nil(t, record.SubmittedAt)
+
+	quote := &Quote{Request: QuoteRequest{PurchaseRequest: req}, ExpiresAt: time.Now().Add(time.Minute)}
+	_, err = live.PurchaseFromQuote(ctx, quote)
+	assert.ErrorIs(t, err, ErrLiveLocked)
+
+	assert.Zero(t, calls)
+}
+
+func TestUnknownEnvironmentIsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	service := NewVTService("key", "pk", "sk", Environment("prod"))
+	assert.Equal(t, SandboxBaseURL, service.baseURL)
+	assert.Contains(t, buf.String(), `unknown environment: "prod", falling back to sandbox`)
+
+	buf.Reset()
+	NewVTService("key", "pk", "sk", EnvironmentLive)
+	assert.Empty(t, buf.String())
+}
@@ -28,7 +28,11 @@ func init() {
 
 func main() {
 
-	service = vt.NewVTService(apiKey, publicKey, secretKey, vt.Environment(env))
+	var err error
+	service, err = vt.NewVTServiceStrict(apiKey, publicKey, secretKey, vt.Environment(env))
+	if err != nil {
+		log.Fatal(err)
+	}
 	available, err := service.Ping(context.Background())
 
 	if err != nil {
@@ -115,7 +119,7 @@ func GenerateRequestID() {
 }
 
 func VerifyMeterNumber() {
-	customer, err := service.VerifyMeterNumber(context.Background(), "0137200395333", "prepaid", "portharcourt-electric")
+	customer, err := service.VerifyMeterNumber(context.Background(), "0137200395333", vt.MeterTypePrepaid, vt.DiscoPortHarcourt)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -134,7 +138,7 @@ func ServiceVariations() {
 	}
 
 	for i := 0; i < len(services); i++ {
-		fmt.Printf("Code: %s, Min Amount: %s , Variation amount: %s \n", services[i].VariationCode, services[i].FixedPrice, services[i].VariationAmount)
+		fmt.Printf("Code: %s, Min Amount: %s , Variation amount: %v \n", services[i].VariationCode, services[i].FixedPrice, services[i].VariationAmount)
 	}
 }
 
@@ -167,6 +171,6 @@ func ServiceByIdentifier() {
 	}
 
 	for i := 0; i < len(services); i++ {
-		fmt.Printf("Service: %s, Min Amount: %s \n", services[i].Name, services[i].MinimumAmount)
+		fmt.Printf("Service: %s, Min Amount: %v \n", services[i].Name, services[i].MinimumAmount)
 	}
 }
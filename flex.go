@@ -0,0 +1,139 @@
+package vtupass_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// FlexFloat is a number VTPass sends either as a JSON number or as a
+// string such as "1,000.00" or "N500". Empty strings and null decode to 0.
+type FlexFloat float64
+
+func (f *FlexFloat) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*f = 0
+		return nil
+	}
+
+	if data[0] != '"' {
+		var n float64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid number %s: %w", data, err)
+		}
+		*f = FlexFloat(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	cleaned := cleanAmount(s)
+	if cleaned == "" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	*f = FlexFloat(n)
+	return nil
+}
+
+func (f FlexFloat) Float64() float64 {
+	return float64(f)
+}
+
+// FlexString is text VTPass sends either as a JSON string or as a number.
+// Numbers keep their literal form, e.g. 35.50 decodes to "35.50".
+type FlexString string
+
+func (s *FlexString) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*s = ""
+		return nil
+	case data[0] == '"':
+		var v string
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*s = FlexString(v)
+		return nil
+	case data[0] == '{' || data[0] == '[':
+		return fmt.Errorf("invalid string %s", data)
+	}
+	*s = FlexString(data)
+	return nil
+}
+
+func (s FlexString) String() string {
+	return string(s)
+}
+
+// lagos is VTPass's time zone, used when a date carries none or the zone
+// database is unavailable.
+var lagos = time.FixedZone("WAT", 60*60)
+
+var transactionDateLayouts = []string{
+	"2006-01-02 15:04:05.000000",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// UnmarshalJSON accepts both the object form
+// {"date": "2024-01-02 10:00:00.000000", "timezone_type": 3, "timezone": "Africa/Lagos"}
+// and a plain date string.
+func (d *TransactionDate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*d = TransactionDate{}
+		return nil
+	case data[0] == '"':
+		*d = TransactionDate{}
+		return json.Unmarshal(data, &d.Date)
+	}
+
+	type plain TransactionDate
+	var v plain
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*d = TransactionDate(v)
+	return nil
+}
+
+// IsZero reports whether no date was sent.
+func (d TransactionDate) IsZero() bool {
+	return d.Date == ""
+}
+
+// Time parses the date in its time zone, defaulting to Africa/Lagos. A
+// zero TransactionDate returns the zero time.
+func (d TransactionDate) Time() (time.Time, error) {
+	if d.IsZero() {
+		return time.Time{}, nil
+	}
+
+	loc := lagos
+	if d.Timezone != "" {
+		if l, err := time.LoadLocation(d.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	for _, layout := range transactionDateLayouts {
+		if t, err := time.ParseInLocation(layout, d.Date, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid transaction date %q", d.Date)
+}
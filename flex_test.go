@@ -0,0 +1,101 @@
+package vtupass_go
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexFloat(t *testing.T) {
+	tests := map[string]float64{
+		`1500`:         1500,
+		`"1500"`:       1500,
+		`"1,500.50"`:   1500.5,
+		`"N200"`:       200,
+		`""`:           0,
+		`null`:         0,
+		`-3.25`:        -3.25,
+		`"  99.9 "`:    99.9,
+		`"₦12,000.00"`: 12000,
+	}
+	for input, expected := range tests {
+		var f FlexFloat
+		require.NoError(t, json.Unmarshal([]byte(input), &f), input)
+		assert.Equal(t, expected, f.Float64(), input)
+	}
+
+	var f FlexFloat
+	assert.Error(t, json.Unmarshal([]byte(`"abc"`), &f))
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &f))
+}
+
+func TestFlexString(t *testing.T) {
+	tests := map[string]string{
+		`"30 kWh"`: "30 kWh",
+		`35.50`:    "35.50",
+		`12`:       "12",
+		`null`:     "",
+	}
+	for input, expected := range tests {
+		var s FlexString
+		require.NoError(t, json.Unmarshal([]byte(input), &s), input)
+		assert.Equal(t, expected, s.String(), input)
+	}
+
+	var s FlexString
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1}`), &s))
+}
+
+func TestTransactionDate(t *testing.T) {
+	var object TransactionDate
+	require.NoError(t, json.Unmarshal([]byte(`{"date":"2024-03-05 14:30:00.000000","timezone_type":3,"timezone":"Africa/Lagos"}`), &object))
+	assert.Equal(t, "Africa/Lagos", object.Timezone)
+	at, err := object.Time()
+	require.NoError(t, err)
+	assert.True(t, at.Equal(time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC)), at)
+
+	var plain TransactionDate
+	require.NoError(t, json.Unmarshal([]byte(`"2024-03-05T14:30:00+01:00"`), &plain))
+	at, err = plain.Time()
+	require.NoError(t, err)
+	assert.True(t, at.Equal(time.Date(2024, 3, 5, 13, 30, 0, 0, time.UTC)), at)
+
+	var missing TransactionDate
+	require.NoError(t, json.Unmarshal([]byte(`null`), &missing))
+	assert.True(t, missing.IsZero())
+}
+
+func TestPayResponseDecodesMixedTypes(t *testing.T) {
+	body := `{
+		"code": "000",
+		"amount": "1,000.00",
+		"transaction_date": {"date": "2024-03-05 14:30:00.000000", "timezone_type": 3, "timezone": "Africa/Lagos"},
+		"units": 35.5,
+		"vat": 0,
+		"content": {"transactions": {
+			"amount": 1000,
+			"unit_price": "1000",
+			"convenience_fee": "0.00",
+			"total_amount": "990.00",
+			"quantity": 1,
+			"status": "delivered"
+		}}
+	}`
+
+	var resp PayResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, 1000.0, resp.Amount.Float64())
+	assert.Equal(t, FlexString("35.5"), resp.Units)
+	assert.Equal(t, FlexString("0"), resp.VAT)
+	assert.Equal(t, "2024-03-05 14:30:00.000000", resp.TransactionDate.Date)
+	assert.Equal(t, 990.0, resp.Content.Transactions.TotalAmount.Float64())
+	assert.Equal(t, 1000.0, resp.Content.Transactions.UnitPrice.Float64())
+
+	var requery TransactionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"000","amount":500,"transaction_date":"2024-03-05 14:30:00"}`), &requery))
+	assert.Equal(t, 500.0, requery.Amount.Float64())
+	assert.Equal(t, "2024-03-05 14:30:00", requery.TransactionDate.Date)
+}
@@ -0,0 +1,127 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	vt "github.com/CeoFred/vtpass-go"
+	vtpassv1 "github.com/CeoFred/vtpass-go/proto/vtpass/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var statuses = map[vt.TransactionStatus]vtpassv1.TransactionStatus{
+	vt.StatusInitiated: vtpassv1.TransactionStatus_TRANSACTION_STATUS_INITIATED,
+	vt.StatusPending:   vtpassv1.TransactionStatus_TRANSACTION_STATUS_PENDING,
+	vt.StatusDelivered: vtpassv1.TransactionStatus_TRANSACTION_STATUS_DELIVERED,
+	vt.StatusFailed:    vtpassv1.TransactionStatus_TRANSACTION_STATUS_FAILED,
+	vt.StatusReversed:  vtpassv1.TransactionStatus_TRANSACTION_STATUS_REVERSED,
+}
+
+var statusNames = func() map[vtpassv1.TransactionStatus]vt.TransactionStatus {
+	names := make(map[vtpassv1.TransactionStatus]vt.TransactionStatus, len(statuses))
+	for name, value := range statuses {
+		names[value] = name
+	}
+	return names
+}()
+
+func fromRecord(record *vt.TransactionRecord) *vtpassv1.Transaction {
+	txn := fromTransaction(record.RequestID, record.Status, record.Transaction, "", record.Response)
+	txn.ServiceId = record.ServiceID
+	if txn.Phone == "" {
+		txn.Phone = record.Phone
+	}
+	if txn.AmountKobo == 0 {
+		txn.AmountKobo = int64(vt.NewMoney(record.Amount))
+	}
+	return txn
+}
+
+func fromTransaction(requestID string, status vt.TransactionStatus, txn vt.Transaction, purchasedCode string, raw []byte) *vtpassv1.Transaction {
+	return &vtpassv1.Transaction{
+		RequestId:       requestID,
+		Status:          statuses[status],
+		TransactionId:   txn.TransactionID,
+		ProductName:     txn.ProductName,
+		UniqueElement:   txn.UniqueElement,
+		AmountKobo:      int64(vt.NewMoney(txn.Amount.Float64())),
+		TotalAmountKobo: int64(vt.NewMoney(txn.TotalAmount.Float64())),
+		Phone:           txn.Phone,
+		PurchasedCode:   purchasedCode,
+		RawResponse:     raw,
+	}
+}
+
+// toStatus maps a VTService error onto a gRPC status.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var errorResponse vt.ErrorResponse
+	switch {
+	case errors.As(err, &errorResponse):
+		code := codes.Unavailable
+		switch errorResponse.Code {
+		case vt.INVALID_ARGUMENTS, vt.PRODUCT_DOES_NOT_EXIST, vt.BELOW_MINIMUM_AMOUNT, vt.ABOVE_MAXIMUM_AMOUNT:
+			code = codes.InvalidArgument
+		case vt.REQUEST_ID_ALREADY_EXISTS, vt.LIKELY_DUPLICATE_TRANSACTION:
+			code = codes.AlreadyExists
+		case vt.LOW_WALLET_BALANCE:
+			code = codes.FailedPrecondition
+		case vt.TRANSACTION_FAILED:
+			code = codes.Aborted
+		case vt.INVALID_REQUEST_ID:
+			code = codes.NotFound
+		}
+		return status.Error(code, errorResponse.Error())
+	case errors.Is(err, vt.ErrUnknownDisco), errors.Is(err, vt.ErrInvalidMeterType), errors.Is(err, vt.ErrInvalidMeterNumber):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, vt.ErrTransactionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, vt.ErrDuplicateIdempotencyKey):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// APIKeyAuth returns server options rejecting calls whose "x-api-key"
+// metadata is not one of keys. Empty keys are ignored, so with no keys
+// every call is rejected.
+func APIKeyAuth(keys ...string) []grpc.ServerOption {
+	authorize := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, got := range md.Get("x-api-key") {
+			for _, key := range keys {
+				if key != "" && subtle.ConstantTimeCompare([]byte(got), []byte(key)) == 1 {
+					return nil
+				}
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid API key")
+	}
+
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorize(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,339 @@
+// Package grpcserver implements the VTPassService gRPC API described in
+// proto/vtpass/v1/vtpass.proto on top of a VTService. Clients for other
+// languages are generated from the same file.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+	vtpassv1 "github.com/CeoFred/vtpass-go/proto/vtpass/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures a Server.
+type Options struct {
+	// PollInterval is how often WatchTransaction requeries a purchase that
+	// is not final. Defaults to 5 seconds.
+	PollInterval time.Duration
+}
+
+// Server implements vtpassv1.VTPassServiceServer.
+type Server struct {
+	vtpassv1.UnimplementedVTPassServiceServer
+
+	service  *vt.VTService
+	opts     Options
+	mu       sync.Mutex
+	watchers map[string][]chan struct{}
+	// flights holds the idempotency keys of purchases in progress. Each
+	// channel is closed when its purchase returns.
+	flights map[string]chan struct{}
+}
+
+var _ vtpassv1.VTPassServiceServer = (*Server)(nil)
+
+// New returns a Server for service.
+func New(service *vt.VTService, opts Options) *Server {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	return &Server{
+		service:  service,
+		opts:     opts,
+		watchers: make(map[string][]chan struct{}),
+		flights:  make(map[string]chan struct{}),
+	}
+}
+
+// Notify wakes WatchTransaction streams for requestID so they report a
+// change without waiting for the next poll. Call it when a webhook for the
+// transaction arrives.
+func (s *Server) Notify(requestID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ch := range s.watchers[requestID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Server) GetBalance(ctx context.Context, req *vtpassv1.GetBalanceRequest) (*vtpassv1.GetBalanceResponse, error) {
+	balance, err := s.service.Balance(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	amount, err := vt.ParseMoney(balance.Contents.Balance)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &vtpassv1.GetBalanceResponse{BalanceKobo: int64(amount)}, nil
+}
+
+func (s *Server) ListServices(ctx context.Context, req *vtpassv1.ListServicesRequest) (*vtpassv1.ListServicesResponse, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "identifier is required")
+	}
+
+	services, err := s.service.ServiceByIdentifier(ctx, req.GetIdentifier())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &vtpassv1.ListServicesResponse{}
+	for _, service := range services {
+		resp.Services = append(resp.Services, &vtpassv1.Service{
+			ServiceId:         service.ServiceID,
+			Name:              service.Name,
+			MinimumAmountKobo: int64(vt.NewMoney(service.MinimumAmount.Float64())),
+			MaximumAmountKobo: int64(vt.NewMoney(service.MaximumAmount.Float64())),
+			ConvenienceFee:    service.ConvenienceFee.String(),
+			ProductType:       service.ProductType,
+			Image:             service.Image,
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) ListVariations(ctx context.Context, req *vtpassv1.ListVariationsRequest) (*vtpassv1.ListVariationsResponse, error) {
+	if req.GetServiceId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "service_id is required")
+	}
+
+	variations, err := s.service.ServiceVariations(ctx, req.GetServiceId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &vtpassv1.ListVariationsResponse{}
+	for _, variation := range variations {
+		resp.Variations = append(resp.Variations, &vtpassv1.Variation{
+			VariationCode: variation.VariationCode,
+			Name:          variation.Name,
+			AmountKobo:    int64(vt.NewMoney(variation.VariationAmount.Float64())),
+			FixedPrice:    strings.EqualFold(variation.FixedPrice, "Yes"),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) VerifyCustomer(ctx context.Context, req *vtpassv1.VerifyCustomerRequest) (*vtpassv1.VerifyCustomerResponse, error) {
+	if req.GetServiceId() == "" || req.GetBillersCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "service_id and billers_code are required")
+	}
+
+	var (
+		info *vt.CustomerInfo
+		err  error
+	)
+	if disco, parseErr := vt.ParseDisco(req.GetServiceId()); parseErr == nil {
+		info, err = s.service.VerifyMeterNumber(ctx, req.GetBillersCode(), vt.MeterType(req.GetType()), disco)
+	} else {
+		info, err = s.service.VerifyCustomer(ctx, req.GetServiceId(), req.GetBillersCode(), req.GetType())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if info.WrongBillersCode || info.Error != "" {
+		return nil, status.Errorf(codes.NotFound, "customer %s not found: %s", req.GetBillersCode(), info.Error)
+	}
+
+	customer := &vtpassv1.Customer{
+		Name:              info.CustomerName,
+		Address:           info.Address,
+		CustomerNumber:    info.CustomerNumber,
+		MeterNumber:       info.MeterNumber,
+		CurrentBouquet:    info.CurrentBouquet,
+		DueDate:           info.DueDate,
+		RenewalAmountKobo: int64(vt.NewMoney(info.RenewalAmount.Float64())),
+		Status:            info.Status,
+	}
+	if arrears, err := vt.ParseMoney(info.CustomerArrears); err == nil {
+		customer.ArrearsKobo = int64(arrears)
+	}
+	return &vtpassv1.VerifyCustomerResponse{Customer: customer}, nil
+}
+
+func (s *Server) Purchase(ctx context.Context, req *vtpassv1.PurchaseRequest) (*vtpassv1.PurchaseResponse, error) {
+	if req.GetServiceId() == "" || req.GetPhone() == "" {
+		return nil, status.Error(codes.InvalidArgument, "service_id and phone are required")
+	}
+
+	if key := req.GetIdempotencyKey(); key != "" {
+		release, err := s.claim(ctx, key)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		defer release()
+
+		if record, err := s.service.TransactionByIdempotencyKey(ctx, key); err == nil {
+			return &vtpassv1.PurchaseResponse{Transaction: fromRecord(record)}, nil
+		}
+	}
+
+	purchase := vt.PurchaseRequest{
+		RequestID:        req.GetRequestId(),
+		ServiceID:        req.GetServiceId(),
+		BillersCode:      req.GetBillersCode(),
+		VariationCode:    req.GetVariationCode(),
+		Amount:           vt.Money(req.GetAmountKobo()).Naira(),
+		Phone:            req.GetPhone(),
+		SubscriptionType: req.GetSubscriptionType(),
+		Quantity:         int(req.GetQuantity()),
+		IdempotencyKey:   req.GetIdempotencyKey(),
+	}
+	if purchase.RequestID == "" {
+		purchase.RequestID = s.service.GenerateRequestID()
+	}
+
+	resp, err := s.service.Purchase(ctx, purchase)
+	if errors.Is(err, vt.ErrDuplicateIdempotencyKey) {
+		// Another gateway instance recorded the key first.
+		if record, lookupErr := s.service.TransactionByIdempotencyKey(ctx, purchase.IdempotencyKey); lookupErr == nil {
+			return &vtpassv1.PurchaseResponse{Transaction: fromRecord(record)}, nil
+		}
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	txn := fromTransaction(purchase.RequestID, resp.Status(), resp.Content.Transactions, resp.PurchasedCode, resp.Raw)
+	txn.ServiceId = purchase.ServiceID
+	return &vtpassv1.PurchaseResponse{Transaction: txn}, nil
+}
+
+// claim waits until no other Purchase holds key, then holds it until the
+// returned function is called.
+func (s *Server) claim(ctx context.Context, key string) (func(), error) {
+	for {
+		s.mu.Lock()
+		busy, ok := s.flights[key]
+		if !ok {
+			done := make(chan struct{})
+			s.flights[key] = done
+			s.mu.Unlock()
+			return func() {
+				s.mu.Lock()
+				delete(s.flights, key)
+				s.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *Server) QueryTransaction(ctx context.Context, req *vtpassv1.QueryTransactionRequest) (*vtpassv1.QueryTransactionResponse, error) {
+	if req.GetRequestId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "request_id is required")
+	}
+
+	txn, err := s.lookup(ctx, req.GetRequestId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &vtpassv1.QueryTransactionResponse{Transaction: txn}, nil
+}
+
+func (s *Server) WatchTransaction(req *vtpassv1.WatchTransactionRequest, stream vtpassv1.VTPassService_WatchTransactionServer) error {
+	requestID := req.GetRequestId()
+	if requestID == "" {
+		return status.Error(codes.InvalidArgument, "request_id is required")
+	}
+
+	ctx := stream.Context()
+	wake := s.watch(requestID)
+	defer s.unwatch(requestID, wake)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	last := vtpassv1.TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+	for {
+		txn, err := s.lookup(ctx, requestID)
+		if err != nil {
+			return toStatus(err)
+		}
+		if txn.GetStatus() != last {
+			if err := stream.Send(&vtpassv1.TransactionEvent{Transaction: txn}); err != nil {
+				return err
+			}
+			last = txn.GetStatus()
+		}
+		// A delivered purchase can still be reversed, but VTPass only
+		// reports that through webhooks, so the stream ends here.
+		if statusNames[last].IsFinal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return toStatus(ctx.Err())
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+func (s *Server) watch(requestID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.watchers[requestID] = append(s.watchers[requestID], ch)
+	return ch
+}
+
+func (s *Server) unwatch(requestID string, ch chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watchers := s.watchers[requestID]
+	for i, w := range watchers {
+		if w == ch {
+			watchers = append(watchers[:i], watchers[i+1:]...)
+			break
+		}
+	}
+	if len(watchers) == 0 {
+		delete(s.watchers, requestID)
+	} else {
+		s.watchers[requestID] = watchers
+	}
+}
+
+// lookup returns a purchase from the transaction store, or from VTPass when
+// the service has no store.
+func (s *Server) lookup(ctx context.Context, requestID string) (*vtpassv1.Transaction, error) {
+	record, err := s.service.Transaction(ctx, requestID)
+	if err == nil {
+		return fromRecord(record), nil
+	}
+	if !errors.Is(err, vt.ErrNoTransactionStore) {
+		return nil, err
+	}
+
+	resp, err := s.service.QueryTransaction(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code == vt.INVALID_REQUEST_ID {
+		return nil, vt.ErrTransactionNotFound
+	}
+	return fromTransaction(requestID, resp.Status(), resp.Content.Transactions, resp.PurchasedCode, resp.Raw), nil
+}
@@ -0,0 +1,236 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+	"github.com/CeoFred/vtpass-go/internal/vtpasstest"
+	vtpassv1 "github.com/CeoFred/vtpass-go/proto/vtpass/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server over an in-memory listener and returns a
+// client for it. VTPass calls are answered by handle, keyed by endpoint.
+func newTestClient(t *testing.T, store vt.TransactionStore, handle vtpasstest.Handler) (vtpassv1.VTPassServiceClient, *Server) {
+	var opts []vt.Option
+	if store != nil {
+		opts = append(opts, vt.WithTransactionStore(store))
+	}
+	srv := New(vtpasstest.NewService(t, handle, opts...), Options{PollInterval: 10 * time.Millisecond})
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(APIKeyAuth("internal-key")...)
+	vtpassv1.RegisterVTPassServiceServer(grpcServer, srv)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return vtpassv1.NewVTPassServiceClient(conn), srv
+}
+
+func authed() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "internal-key")
+}
+
+func TestAuthAndCatalog(t *testing.T) {
+	client, _ := newTestClient(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		switch endpoint {
+		case "balance":
+			return http.StatusOK, `{"code":"000","contents":{"balance":"1,500.25"}}`
+		case "service-variations?serviceID=mtn-data":
+			return http.StatusOK, `{"code":"000","content":{"ServiceName":"MTN Data","varations":[{"variation_code":"mtn-10mb-100","name":"10MB","variation_amount":"100.00","fixedPrice":"Yes"}]}}`
+		}
+		return http.StatusNotFound, `{}`
+	})
+
+	_, err := client.GetBalance(context.Background(), &vtpassv1.GetBalanceRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	balance, err := client.GetBalance(authed(), &vtpassv1.GetBalanceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(150025), balance.GetBalanceKobo())
+
+	variations, err := client.ListVariations(authed(), &vtpassv1.ListVariationsRequest{ServiceId: "mtn-data"})
+	require.NoError(t, err)
+	require.Len(t, variations.GetVariations(), 1)
+	assert.Equal(t, "mtn-10mb-100", variations.GetVariations()[0].GetVariationCode())
+	assert.Equal(t, int64(10000), variations.GetVariations()[0].GetAmountKobo())
+	assert.True(t, variations.GetVariations()[0].GetFixedPrice())
+
+	_, err = client.ListVariations(authed(), &vtpassv1.ListVariationsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPurchase(t *testing.T) {
+	var pays int32
+	client, _ := newTestClient(t, vt.NewMemoryTransactionStore(), func(endpoint string, body map[string]interface{}) (int, string) {
+		switch endpoint {
+		case "pay":
+			atomic.AddInt32(&pays, 1)
+			if body["phone"] == "08000000000" {
+				return http.StatusOK, `{"code":"018","response_description":"LOW WALLET BALANCE"}`
+			}
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered","transactionId":"17000","amount":100}}}`
+		}
+		return http.StatusNotFound, `{}`
+	})
+
+	req := &vtpassv1.PurchaseRequest{ServiceId: "mtn", AmountKobo: 10000, Phone: "08011111111", IdempotencyKey: "order-1"}
+	first, err := client.Purchase(authed(), req)
+	require.NoError(t, err)
+	assert.Equal(t, vtpassv1.TransactionStatus_TRANSACTION_STATUS_DELIVERED, first.GetTransaction().GetStatus())
+	assert.Equal(t, "17000", first.GetTransaction().GetTransactionId())
+	assert.Equal(t, int64(10000), first.GetTransaction().GetAmountKobo())
+	assert.NotEmpty(t, first.GetTransaction().GetRawResponse())
+
+	again, err := client.Purchase(authed(), req)
+	require.NoError(t, err)
+	assert.Equal(t, first.GetTransaction().GetRequestId(), again.GetTransaction().GetRequestId())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+
+	_, err = client.Purchase(authed(), &vtpassv1.PurchaseRequest{ServiceId: "mtn", AmountKobo: 10000, Phone: "08000000000"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestWatchTransaction(t *testing.T) {
+	var requeries int32
+	client, _ := newTestClient(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		if endpoint != "requery" {
+			return http.StatusNotFound, `{}`
+		}
+		if body["request_id"] == "missing" {
+			return http.StatusOK, `{"code":"015","response_description":"INVALID REQUEST ID"}`
+		}
+		if atomic.AddInt32(&requeries, 1) < 3 {
+			return http.StatusOK, `{"code":"099","content":{"transactions":{"status":"pending"}}}`
+		}
+		return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+	})
+
+	stream, err := client.WatchTransaction(authed(), &vtpassv1.WatchTransactionRequest{RequestId: "202401011200abc"})
+	require.NoError(t, err)
+
+	var got []vtpassv1.TransactionStatus
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, event.GetTransaction().GetStatus())
+	}
+	assert.Equal(t, []vtpassv1.TransactionStatus{
+		vtpassv1.TransactionStatus_TRANSACTION_STATUS_PENDING,
+		vtpassv1.TransactionStatus_TRANSACTION_STATUS_DELIVERED,
+	}, got)
+
+	_, err = client.QueryTransaction(authed(), &vtpassv1.QueryTransactionRequest{RequestId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestNotifyWakesWatchers(t *testing.T) {
+	var delivered atomic.Bool
+	client, srv := newTestClient(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		if delivered.Load() {
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+		}
+		return http.StatusOK, `{"code":"099","content":{"transactions":{"status":"pending"}}}`
+	})
+	srv.opts.PollInterval = time.Hour
+
+	stream, err := client.WatchTransaction(authed(), &vtpassv1.WatchTransactionRequest{RequestId: "abc"})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, vtpassv1.TransactionStatus_TRANSACTION_STATUS_PENDING, event.GetTransaction().GetStatus())
+
+	delivered.Store(true)
+	srv.Notify("abc")
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, vtpassv1.TransactionStatus_TRANSACTION_STATUS_DELIVERED, event.GetTransaction().GetStatus())
+}
+
+func TestConcurrentPurchasesWithSameKey(t *testing.T) {
+	var pays int32
+	client, _ := newTestClient(t, vt.NewMemoryTransactionStore(), func(endpoint string, body map[string]interface{}) (int, string) {
+		if endpoint != "pay" {
+			return http.StatusNotFound, `{}`
+		}
+		atomic.AddInt32(&pays, 1)
+		time.Sleep(20 * time.Millisecond)
+		return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered","transactionId":"17000"}}}`
+	})
+
+	req := &vtpassv1.PurchaseRequest{ServiceId: "mtn", AmountKobo: 10000, Phone: "08011111111", IdempotencyKey: "order-1"}
+	requestIDs := make(chan string, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Purchase(authed(), req)
+			assert.NoError(t, err)
+			requestIDs <- resp.GetTransaction().GetRequestId()
+		}()
+	}
+	wg.Wait()
+	close(requestIDs)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+	first := <-requestIDs
+	for requestID := range requestIDs {
+		assert.Equal(t, first, requestID)
+	}
+}
+
+func TestAPIKeyAuthWithoutKeys(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(APIKeyAuth("")...)
+	vtpassv1.RegisterVTPassServiceServer(grpcServer, New(vt.NewVTService("key", "pk", "sk", vt.EnvironmentSandbox), Options{}))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := vtpassv1.NewVTPassServiceClient(conn)
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		metadata.AppendToOutgoingContext(context.Background(), "x-api-key", ""),
+		authed(),
+	} {
+		_, err := client.GetBalance(ctx, &vtpassv1.GetBalanceRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
@@ -0,0 +1,102 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HealthStatus classifies the outcome of a health check.
+type HealthStatus string
+
+const (
+	HealthOK HealthStatus = "ok"
+	// HealthAuthFailure means VTPass rejected the credentials (087).
+	HealthAuthFailure HealthStatus = "auth_failure"
+	// HealthNetworkFailure means VTPass could not be reached in time.
+	HealthNetworkFailure HealthStatus = "network_failure"
+	// HealthServerError means VTPass answered with an error status, an
+	// error code or a body that could not be decoded.
+	HealthServerError HealthStatus = "server_error"
+)
+
+// HealthReport is the result of Health.
+type HealthReport struct {
+	Status      HealthStatus  `json:"status"`
+	Environment Environment   `json:"environment"`
+	BaseURL     string        `json:"base_url"`
+	Latency     time.Duration `json:"latency"`
+	// HTTPStatus and Code are those of the balance response, when one was
+	// received.
+	HTTPStatus int       `json:"http_status,omitempty"`
+	Code       string    `json:"code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+	// Err is the error behind a failed check.
+	Err error `json:"-"`
+}
+
+// Healthy reports whether the check passed.
+func (r HealthReport) Healthy() bool {
+	return r.Status == HealthOK
+}
+
+// Health checks that VTPass is reachable and accepts the credentials by
+// fetching the wallet balance. It is suitable for readiness probes; bound
+// it with a context deadline.
+func (s *VTService) Health(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Environment: s.Enviroment,
+		BaseURL:     s.baseURL,
+		CheckedAt:   time.Now(),
+	}
+
+	ctx, raw := withRawCapture(ctx)
+	start := time.Now()
+	_, err := s.Balance(ctx)
+	report.Latency = time.Since(start)
+	report.HTTPStatus = raw.HTTPStatus
+
+	if err == nil {
+		report.Status = HealthOK
+		return report
+	}
+
+	report.Err = err
+	report.Error = err.Error()
+	report.Status = healthStatus(err)
+
+	var errorResponse ErrorResponse
+	if errors.As(err, &errorResponse) {
+		report.Code = errorResponse.Code
+	}
+	return report
+}
+
+func healthStatus(err error) HealthStatus {
+	var (
+		errorResponse ErrorResponse
+		httpErr       *HTTPError
+		urlErr        *url.Error
+		netErr        net.Error
+	)
+	switch {
+	case errors.As(err, &errorResponse):
+		if errorResponse.Code == INVALID_CREDENTIALS {
+			return HealthAuthFailure
+		}
+		return HealthServerError
+	case errors.As(err, &httpErr):
+		if httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden {
+			return HealthAuthFailure
+		}
+		return HealthServerError
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled),
+		errors.As(err, &urlErr), errors.As(err, &netErr):
+		return HealthNetworkFailure
+	}
+	return HealthServerError
+}
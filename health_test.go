@@ -0,0 +1,70 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHealth(t *testing.T) {
+	cases := []struct {
+		name       string
+		respond    func() (*http.Response, error)
+		want       HealthStatus
+		wantCode   string
+		wantStatus int
+	}{
+		{"ok", func() (*http.Response, error) {
+			return rawJSONResponse(http.StatusOK, `{"code":"000","contents":{"balance":"100.00"}}`), nil
+		}, HealthOK, "", http.StatusOK},
+		{"invalid credentials", func() (*http.Response, error) {
+			return rawJSONResponse(http.StatusOK, `{"code":"087","response_description":"INVALID CREDENTIALS"}`), nil
+		}, HealthAuthFailure, INVALID_CREDENTIALS, http.StatusOK},
+		{"unauthorized", func() (*http.Response, error) {
+			return rawJSONResponse(http.StatusUnauthorized, `{}`), nil
+		}, HealthAuthFailure, "", http.StatusUnauthorized},
+		{"server error with decodable body", func() (*http.Response, error) {
+			return rawJSONResponse(http.StatusInternalServerError, `{"contents":{}}`), nil
+		}, HealthServerError, "", http.StatusInternalServerError},
+		{"undecodable body", func() (*http.Response, error) {
+			return rawJSONResponse(http.StatusOK, `<html></html>`), nil
+		}, HealthServerError, "", http.StatusOK},
+		{"network failure", func() (*http.Response, error) {
+			return nil, errors.New("dial tcp: connection refused")
+		}, HealthNetworkFailure, "", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return tc.respond()
+			})
+			service := NewVTService("key", "pk", "sk", EnvironmentLive,
+				WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport})))
+
+			report := service.Health(context.Background())
+			assert.Equal(t, tc.want, report.Status)
+			assert.Equal(t, tc.wantCode, report.Code)
+			assert.Equal(t, tc.wantStatus, report.HTTPStatus)
+			assert.Equal(t, EnvironmentLive, report.Environment)
+			assert.Equal(t, LiveEnviromentURL, report.BaseURL)
+			assert.Equal(t, tc.want == HealthOK, report.Healthy())
+			assert.Equal(t, tc.want == HealthOK, report.Err == nil)
+
+			ok, err := service.Ping(context.Background())
+			assert.Equal(t, report.Healthy(), ok)
+			assert.Equal(t, report.Healthy(), err == nil)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Package vtpasstest provides a VTService for tests whose calls to VTPass
+// are answered in memory.
+package vtpasstest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	vt "github.com/CeoFred/vtpass-go"
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Handler answers a VTPass call. endpoint is the path after /api/,
+// followed by the query string if there is one, and body is the decoded
+// JSON body of the request. It returns the HTTP status and body of the
+// response.
+type Handler func(endpoint string, body map[string]interface{}) (int, string)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewService returns a sandbox VTService whose requests are answered by
+// handle. opts are applied after the test transport.
+func NewService(t testing.TB, handle Handler, opts ...vt.Option) *vt.VTService {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := map[string]interface{}{}
+		if req.Body != nil {
+			data, _ := io.ReadAll(req.Body)
+			if len(data) > 0 {
+				require.NoError(t, json.Unmarshal(data, &body))
+			}
+		}
+		endpoint := strings.TrimPrefix(req.URL.Path, "/api/")
+		if req.URL.RawQuery != "" {
+			endpoint += "?" + req.URL.RawQuery
+		}
+		status, resp := handle(endpoint, body)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(resp)),
+			Request:    req,
+		}, nil
+	})
+
+	opts = append([]vt.Option{vt.WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport}))}, opts...)
+	service, err := vt.NewVTServiceStrict("key", "pk", "sk", vt.EnvironmentSandbox, opts...)
+	require.NoError(t, err)
+	return service
+}
@@ -25,18 +25,58 @@ var (
 
 // APIClient is a wrapper for making HTTP requests to the API.
 type APIClient struct {
-	baseURL string
-	apiKey  string
-	client  *http.Client
+	baseURL    string
+	apiKey     string
+	client     *http.Client
+	middleware []Middleware
+	// endpointLimits and limits are chained after middleware, endpoint
+	// limits first, whatever order the options were given in.
+	endpointLimits []Middleware
+	limits         []Middleware
+	doer           Doer
+}
+
+// Option configures an APIClient.
+type Option func(*APIClient)
+
+// WithHTTPClient replaces the underlying *http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *APIClient) {
+		c.client = client
+	}
+}
+
+// WithLimit throttles every request sent by the client. It applies after
+// any middleware and endpoint limits.
+func WithLimit(limit Limit) Option {
+	return func(c *APIClient) {
+		c.limits = append(c.limits, RateLimit(limit))
+	}
+}
+
+// WithEndpointLimit throttles requests to a single endpoint, e.g. "pay" or
+// "service-variations". It applies in addition to the global limit. The
+// endpoint slot is always taken before the global one, so requests
+// waiting on a busy endpoint do not hold up requests to other endpoints.
+func WithEndpointLimit(endpoint string, limit Limit) Option {
+	return func(c *APIClient) {
+		c.endpointLimits = append(c.endpointLimits, EndpointRateLimit(endpoint, limit))
+	}
 }
 
 // NewAPIClient creates a new instance of APIClient.
-func NewAPIClient(baseURL, apiKey string) *APIClient {
-	return &APIClient{
+func NewAPIClient(baseURL, apiKey string, opts ...Option) *APIClient {
+	c := &APIClient{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		client:  defaultClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	middleware := append(append(append([]Middleware{}, c.middleware...), c.endpointLimits...), c.limits...)
+	c.doer = Chain(c.client, middleware...)
+	return c
 }
 
 // Helper function to convert variadic headers to a map
@@ -60,7 +100,7 @@ func (c *APIClient) Put(ctx context.Context, endpoint string, payload interface{
 		req.Header.Set(key, value)
 	}
 
-	return c.client.Do(req)
+	return c.doer.Do(req)
 }
 
 func (c *APIClient) Patch(ctx context.Context, endpoint string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
@@ -82,7 +122,7 @@ func (c *APIClient) Patch(ctx context.Context, endpoint string, payload interfac
 		req.Header.Set(key, value)
 	}
 
-	return c.client.Do(req)
+	return c.doer.Do(req)
 }
 
 // Post sends a POST request to the specified endpoint with the given payload.
@@ -105,7 +145,7 @@ func (c *APIClient) Post(ctx context.Context, endpoint string, payload interface
 		req.Header.Set(key, value)
 	}
 
-	return c.client.Do(req)
+	return c.doer.Do(req)
 }
 
 func (c *APIClient) Delete(ctx context.Context, endpoint string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
@@ -127,7 +167,7 @@ func (c *APIClient) Delete(ctx context.Context, endpoint string, payload interfa
 		req.Header.Set(key, value)
 	}
 
-	return c.client.Do(req)
+	return c.doer.Do(req)
 }
 
 // Get sends a GET request to the specified endpoint, appending id as a path parameter
@@ -144,5 +184,5 @@ func (c *APIClient) Get(ctx context.Context, path string, headers ...map[string]
 		req.Header.Set(key, value)
 	}
 
-	return c.client.Do(req)
+	return c.doer.Do(req)
 }
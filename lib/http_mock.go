@@ -3,6 +3,8 @@ package httpclient
 import (
 	"context"
 	"net/http"
+
+	utils "github.com/CeoFred/vtpass-go/utils"
 )
 
 // MockClient is a mock HTTP client to replace the real one during tests.
@@ -12,25 +14,33 @@ type MockClient struct {
 	DeleteFunc func(ctx context.Context, path string, payload interface{}) (*http.Response, error)
 	PutFunc    func(ctx context.Context, path string, payload interface{}) (*http.Response, error)
 	PatchFunc  func(ctx context.Context, path string, payload interface{}) (*http.Response, error)
+
+	// LastHeaders holds the headers passed to the most recent request.
+	LastHeaders map[string]string
 }
 
 func (m *MockClient) Get(ctx context.Context, path string, headers ...map[string]string) (*http.Response, error) {
+	m.LastHeaders = utils.HeadersToMap(headers...)
 	return m.GetFunc(ctx, path)
 }
 
 func (m *MockClient) Post(ctx context.Context, path string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
+	m.LastHeaders = utils.HeadersToMap(headers...)
 	return m.PostFunc(ctx, path, payload)
 }
 
 func (m *MockClient) Delete(ctx context.Context, path string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
+	m.LastHeaders = utils.HeadersToMap(headers...)
 	return m.DeleteFunc(ctx, path, payload)
 }
 
 func (m *MockClient) Put(ctx context.Context, path string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
+	m.LastHeaders = utils.HeadersToMap(headers...)
 	return m.PutFunc(ctx, path, payload)
 }
 
 func (m *MockClient) Patch(ctx context.Context, path string, payload interface{}, headers ...map[string]string) (*http.Response, error) {
+	m.LastHeaders = utils.HeadersToMap(headers...)
 	return m.PatchFunc(ctx, path, payload)
 }
 
@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit configures client-side throttling for a group of requests.
+// A zero Rate disables the token bucket and a zero MaxInFlight disables
+// the concurrency cap.
+type Limit struct {
+	// Rate is the sustained number of requests allowed per second.
+	Rate float64
+	// Burst is the number of requests that may be sent back to back
+	// before Rate applies. It defaults to 1 when Rate is set.
+	Burst int
+	// MaxInFlight caps the number of requests whose response body has
+	// not yet been closed.
+	MaxInFlight int
+}
+
+// tokenBucket is a minimal token-bucket rate limiter.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// limiter combines a token bucket and an in-flight semaphore.
+type limiter struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+func newLimiter(l Limit) *limiter {
+	if l.Rate <= 0 && l.MaxInFlight <= 0 {
+		return nil
+	}
+
+	lim := &limiter{}
+	if l.Rate > 0 {
+		lim.bucket = newTokenBucket(l.Rate, l.Burst)
+	}
+	if l.MaxInFlight > 0 {
+		lim.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	return lim
+}
+
+// acquire waits for an in-flight slot and a token. The returned function
+// releases the slot and must be called exactly once.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if l.bucket != nil {
+		if err := l.bucket.wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// RateLimit returns middleware that throttles every request passing
+// through it to limit. The in-flight slot is held until the response body
+// is closed.
+func RateLimit(limit Limit) Middleware {
+	return limitRequests(newLimiter(limit), func(*http.Request) bool { return true })
+}
+
+// EndpointRateLimit returns middleware that throttles requests to a single
+// endpoint, e.g. "pay" or "service-variations", to limit. Other requests
+// pass through untouched.
+func EndpointRateLimit(endpoint string, limit Limit) Middleware {
+	endpoint = endpointKey(endpoint)
+	return limitRequests(newLimiter(limit), func(req *http.Request) bool {
+		key := endpointKey(req.URL.Path)
+		return key == endpoint || strings.HasSuffix(key, "/"+endpoint)
+	})
+}
+
+func limitRequests(l *limiter, match func(*http.Request) bool) Middleware {
+	return func(next Doer) Doer {
+		if l == nil {
+			return next
+		}
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if !match(req) {
+				return next.Do(req)
+			}
+
+			release, err := l.acquire(req.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				release()
+				return nil, err
+			}
+
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		})
+	}
+}
+
+// endpointKey reduces a request path such as "service-variations?serviceID=x"
+// to the endpoint name used for per-endpoint limits.
+func endpointKey(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.Trim(path, "/")
+}
+
+// releaseOnClose frees an in-flight slot once the response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
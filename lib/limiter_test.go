@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointKey(t *testing.T) {
+	assert.Equal(t, "service-variations", endpointKey("service-variations?serviceID=enugu-electric"))
+	assert.Equal(t, "pay", endpointKey("/pay/"))
+	assert.Equal(t, "balance", endpointKey("balance"))
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL+"/", "key", WithEndpointLimit("pay", Limit{MaxInFlight: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Post(context.Background(), "pay", map[string]string{})
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestRateLimitRespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL+"/", "key", WithLimit(Limit{Rate: 0.1, Burst: 1}))
+
+	resp, err := client.Get(context.Background(), "balance")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Get(ctx, "balance")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestEndpointLimitIsTakenBeforeGlobalLimit(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pay" {
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	// The global limit is registered first, but a pay request waiting on the
+	// pay slot must not hold one of the two global slots.
+	client := NewAPIClient(server.URL+"/", "key",
+		WithLimit(Limit{MaxInFlight: 2}),
+		WithEndpointLimit("pay", Limit{MaxInFlight: 1}),
+	)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			resp, err := client.Post(context.Background(), "pay", map[string]string{})
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.Get(ctx, "balance")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Doer sends an HTTP request. *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts an ordinary function to Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to observe or change requests and responses,
+// e.g. to log, retry, inject headers or throttle.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware registers middleware on the client. The first middleware
+// registered is the outermost and sees each request first.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *APIClient) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// Chain wraps doer in middleware, the first being the outermost.
+func Chain(doer Doer, middleware ...Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
+
+// Headers returns middleware that sets headers on every request that does
+// not already carry them.
+func Headers(headers map[string]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			for key, value := range headers {
+				if req.Header.Get(key) == "" {
+					req.Header.Set(key, value)
+				}
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// Logging returns middleware that logs the method, path, status and
+// duration of every request to logger, or to the standard logger when
+// logger is nil. Headers and bodies are not logged as they carry
+// credentials.
+func Logging(logger *log.Logger) Middleware {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			if err != nil {
+				printf("vtpass: %s %s failed after %s: %v", req.Method, req.URL.Path, time.Since(start), err)
+				return nil, err
+			}
+			printf("vtpass: %s %s %d in %s", req.Method, req.URL.Path, resp.StatusCode, time.Since(start))
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, "server "+r.Header.Get("X-Trace"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tag := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				seen = append(seen, name)
+				req.Header.Set("X-Trace", req.Header.Get("X-Trace")+name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	client := NewAPIClient(server.URL+"/api/", "key", WithMiddleware(tag("a"), tag("b")), WithMiddleware(tag("c")))
+	resp, err := client.Get(context.Background(), "balance")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"a", "b", "c", "server abc"}, seen)
+}
+
+func TestHeadersAndLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vtpass-go", r.Header.Get("User-Agent"))
+		assert.Equal(t, "explicit", r.Header.Get("X-Request-Source"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewAPIClient(server.URL+"/api/", "key", WithMiddleware(
+		Logging(log.New(&buf, "", 0)),
+		Headers(map[string]string{"User-Agent": "vtpass-go", "X-Request-Source": "default"}),
+	))
+
+	resp, err := client.Post(context.Background(), "pay", map[string]string{}, map[string]string{"X-Request-Source": "explicit", "api-key": "secret"})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "vtpass: POST /api/pay 418", strings.SplitN(buf.String(), " in ", 2)[0])
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestEndpointRateLimitMatchesPath(t *testing.T) {
+	var passed []string
+	mw := EndpointRateLimit("pay", Limit{MaxInFlight: 1})
+	doer := mw(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		passed = append(passed, req.URL.Path)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+
+	// The slot for /api/pay is held until the body is closed, so a second
+	// pay request would block; other endpoints are not limited.
+	req := httptest.NewRequest(http.MethodPost, "https://sandbox.vtpass.com/api/pay", nil)
+	held, err := doer.Do(req)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/api/requery", "/api/prepay", "/api/service-variations"} {
+		resp, err := doer.Do(httptest.NewRequest(http.MethodGet, "https://sandbox.vtpass.com"+path, nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = doer.Do(httptest.NewRequest(http.MethodPost, "https://sandbox.vtpass.com/api/pay", nil).WithContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	held.Body.Close()
+	assert.Equal(t, []string{"/api/pay", "/api/requery", "/api/prepay", "/api/service-variations"}, passed)
+}
@@ -0,0 +1,51 @@
+package vtupass_go
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in naira stored as kobo to avoid float drift when
+// comparing and summing balances.
+type Money int64
+
+// NewMoney converts a naira amount such as 1000.50 to Money.
+func NewMoney(naira float64) Money {
+	return Money(math.Round(naira * 100))
+}
+
+// ParseMoney parses VTPass amounts such as "12,345.60", "N1000" or "₦500".
+func ParseMoney(value string) (Money, error) {
+	naira, err := strconv.ParseFloat(cleanAmount(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money value %q: %w", value, err)
+	}
+	return NewMoney(naira), nil
+}
+
+// cleanAmount strips the currency prefix, thousands separators and
+// surrounding space from an amount.
+func cleanAmount(value string) string {
+	cleaned := strings.TrimSpace(value)
+	cleaned = strings.TrimPrefix(cleaned, "₦")
+	cleaned = strings.TrimPrefix(cleaned, "NGN")
+	cleaned = strings.TrimPrefix(cleaned, "N")
+	cleaned = strings.ReplaceAll(cleaned, ",", "")
+	return strings.TrimSpace(cleaned)
+}
+
+// Naira returns the amount in naira.
+func (m Money) Naira() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
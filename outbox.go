@@ -0,0 +1,242 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrOutboxStore is returned by NewOutbox when the service has no store or
+// its store cannot claim records atomically.
+var ErrOutboxStore = errors.New("outbox requires a transaction store implementing OutboxStore")
+
+// OutboxOptions configures an Outbox.
+type OutboxOptions struct {
+	// StaleAfter is how long an intent may stay unsubmitted, or a
+	// submitted purchase unresolved, before Recover treats it as
+	// interrupted. It keeps Recover away from purchases that are still in
+	// flight. Defaults to one minute.
+	StaleAfter time.Duration
+	// Interval between Recover runs in Run. Defaults to 30 seconds.
+	Interval time.Duration
+}
+
+// OutboxReport counts what a Recover run did.
+type OutboxReport struct {
+	// Submitted intents had never been sent and were paid.
+	Submitted int
+	// Resolved intents had been sent and were settled through requery.
+	Resolved int
+	// Resubmitted intents had been claimed but never reached VTPass, as
+	// confirmed by requery, and were sent again under the same request ID.
+	Resubmitted int
+	// Unresolved intents could not be settled and are retried next run.
+	Unresolved int
+}
+
+// Outbox makes purchase submission crash-safe. The intent to pay is stored
+// before pay is called and claimed atomically when it is sent, so after a
+// restart interrupted purchases are resolved with QueryTransaction rather
+// than paid a second time.
+type Outbox struct {
+	service *VTService
+	store   OutboxStore
+	opts    OutboxOptions
+}
+
+// NewOutbox returns an Outbox for service. The service must have been
+// created WithTransactionStore using a store that implements OutboxStore.
+func NewOutbox(service *VTService, opts OutboxOptions) (*Outbox, error) {
+	store, ok := service.store.(OutboxStore)
+	if !ok {
+		return nil, ErrOutboxStore
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = time.Minute
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	return &Outbox{service: service, store: store, opts: opts}, nil
+}
+
+// Enqueue records the intent to make req. A request ID is generated when
+// empty. Enqueueing a request ID that already exists returns the existing
+// record unchanged.
+func (o *Outbox) Enqueue(ctx context.Context, req PurchaseRequest) (*TransactionRecord, error) {
+	if err := o.service.checkLive(); err != nil {
+		return nil, err
+	}
+	if req.RequestID == "" {
+		req.RequestID = o.service.GenerateRequestID()
+	}
+
+	if err := o.service.recordPurchase(ctx, req); err != nil {
+		return nil, err
+	}
+	return o.store.Get(ctx, req.RequestID)
+}
+
+// Submit claims an enqueued intent and pays it. If the intent was already
+// claimed, nothing is sent and the current record is returned.
+func (o *Outbox) Submit(ctx context.Context, requestID string) (*TransactionRecord, error) {
+	// Checked before the claim, which would otherwise stamp an intent
+	// that was never sent.
+	if err := o.service.checkLive(); err != nil {
+		return nil, err
+	}
+	claimed, err := o.store.MarkSubmitted(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return o.store.Get(ctx, requestID)
+	}
+	return o.pay(ctx, requestID)
+}
+
+// Purchase enqueues and submits req in one call.
+func (o *Outbox) Purchase(ctx context.Context, req PurchaseRequest) (*TransactionRecord, error) {
+	record, err := o.Enqueue(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return o.Submit(ctx, record.RequestID)
+}
+
+// Recover settles intents left behind by a crash. Enqueued intents never
+// claimed within StaleAfter are submitted. Purchases claimed or sent more
+// than StaleAfter ago, including those made with VTService.Purchase, are
+// requeried; only those VTPass has no record of are sent again.
+func (o *Outbox) Recover(ctx context.Context) (*OutboxReport, error) {
+	report := &OutboxReport{}
+	cutoff := time.Now().Add(-o.opts.StaleAfter)
+
+	for _, status := range []TransactionStatus{StatusInitiated, StatusPending} {
+		records, err := o.listAll(ctx, TransactionFilter{Status: status})
+		if err != nil {
+			return report, err
+		}
+
+		for _, record := range records {
+			switch {
+			case record.SubmittedAt == nil && record.CreatedAt.After(cutoff):
+				// Possibly about to be submitted.
+				continue
+			case record.SubmittedAt == nil:
+				if _, err := o.Submit(ctx, record.RequestID); err != nil {
+					report.Unresolved++
+					continue
+				}
+				report.Submitted++
+			case record.SubmittedAt.After(cutoff):
+				// Possibly still in flight.
+				continue
+			default:
+				o.resolve(ctx, record, report)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// Run calls Recover every Interval until ctx is done.
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.Recover(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox recovery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) resolve(ctx context.Context, record TransactionRecord, report *OutboxReport) {
+	txn, err := o.service.QueryTransaction(ctx, record.RequestID)
+	if err != nil {
+		report.Unresolved++
+		return
+	}
+
+	if txn.Code == INVALID_REQUEST_ID {
+		if _, err := o.pay(ctx, record.RequestID); err != nil {
+			report.Unresolved++
+			return
+		}
+		report.Resubmitted++
+		return
+	}
+
+	status := txn.Status()
+	if status == "" {
+		report.Unresolved++
+		return
+	}
+	record.Status = status
+	record.Transaction = txn.Content.Transactions
+	if err := o.store.Save(ctx, record); err != nil {
+		report.Unresolved++
+		return
+	}
+	report.Resolved++
+}
+
+// pay sends a claimed intent. Purchase records the outcome in the store.
+func (o *Outbox) pay(ctx context.Context, requestID string) (*TransactionRecord, error) {
+	record, err := o.store.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var req PurchaseRequest
+	if err := json.Unmarshal(record.Payload, &req); err != nil {
+		return nil, err
+	}
+	req.RequestID = record.RequestID
+	req.IdempotencyKey = record.IdempotencyKey
+
+	_, payErr := o.service.Purchase(ctx, req)
+
+	record, err = o.store.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return record, payErr
+}
+
+// markSubmitted stamps a purchase as sent, so an Outbox requeries it
+// instead of submitting it again. Without an OutboxStore there is no
+// outbox to tell.
+func (s *VTService) markSubmitted(ctx context.Context, requestID string) error {
+	store, ok := s.store.(OutboxStore)
+	if !ok {
+		return nil
+	}
+	_, err := store.MarkSubmitted(ctx, requestID)
+	return err
+}
+
+func (o *Outbox) listAll(ctx context.Context, filter TransactionFilter) ([]TransactionRecord, error) {
+	var records []TransactionRecord
+	for {
+		page, err := o.store.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page.Transactions...)
+		if page.NextCursor == "" {
+			return records, nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,169 @@
+package vtupass_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxSubmitsOnce(t *testing.T) {
+	pays := 0
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		pays++
+		var resp PayResponse
+		resp.Code = TRANSACTION_SUCCESSFUL
+		resp.Content.Transactions.Status = "delivered"
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	store := NewMemoryTransactionStore()
+	outbox, err := NewOutbox(&VTService{client: mockClient, store: store}, OutboxOptions{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	record, err := outbox.Enqueue(ctx, PurchaseRequest{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusInitiated, record.Status)
+	assert.Nil(t, record.SubmittedAt)
+
+	record, err = outbox.Submit(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, record.Status)
+	assert.NotNil(t, record.SubmittedAt)
+
+	_, err = outbox.Submit(ctx, "r1")
+	require.NoError(t, err)
+	_, err = outbox.Purchase(ctx, PurchaseRequest{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pays)
+
+	_, err = NewOutbox(&VTService{client: mockClient}, OutboxOptions{})
+	assert.ErrorIs(t, err, ErrOutboxStore)
+}
+
+func TestOutboxRecover(t *testing.T) {
+	var paid []string
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		if path == "requery" {
+			requestID := payload.(map[string]interface{})["request_id"].(string)
+			var txn TransactionResponse
+			if requestID == "lost" {
+				txn.Code = INVALID_REQUEST_ID
+			} else {
+				txn.Code = TRANSACTION_SUCCESSFUL
+				txn.Content.Transactions.Status = "delivered"
+			}
+			return jsonResponse(http.StatusOK, txn), nil
+		}
+
+		paid = append(paid, payload.(PurchaseRequest).RequestID)
+		var resp PayResponse
+		resp.Code = TRANSACTION_SUCCESSFUL
+		resp.Content.Transactions.Status = "delivered"
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+	outbox, err := NewOutbox(service, OutboxOptions{StaleAfter: time.Millisecond})
+	require.NoError(t, err)
+
+	// "sent" crashed after pay reached VTPass, "lost" crashed before it did
+	// and "queued" was never claimed.
+	for _, id := range []string{"sent", "lost", "queued"} {
+		_, err := outbox.Enqueue(ctx, PurchaseRequest{RequestID: id, ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+		require.NoError(t, err)
+	}
+	for _, id := range []string{"sent", "lost"} {
+		claimed, err := store.MarkSubmitted(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	report, err := outbox.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxReport{Submitted: 1, Resolved: 1, Resubmitted: 1}, *report)
+	assert.ElementsMatch(t, []string{"lost", "queued"}, paid)
+
+	for _, id := range []string{"sent", "lost", "queued"} {
+		record, err := store.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, StatusDelivered, record.Status, id)
+	}
+
+	report, err = outbox.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxReport{}, *report)
+	assert.Len(t, paid, 2)
+}
+
+func TestOutboxRecoverRequeriesPurchases(t *testing.T) {
+	pays := 0
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		if path == "requery" {
+			var txn TransactionResponse
+			txn.Code = TRANSACTION_SUCCESSFUL
+			txn.Content.Transactions.Status = "delivered"
+			return jsonResponse(http.StatusOK, txn), nil
+		}
+
+		pays++
+		var resp PayResponse
+		resp.Code = TRANSACTION_PROCESSING
+		resp.Content.Transactions.Status = "pending"
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+	outbox, err := NewOutbox(service, OutboxOptions{StaleAfter: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = service.Purchase(ctx, PurchaseRequest{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	require.NoError(t, err)
+	record, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, record.Status)
+	assert.NotNil(t, record.SubmittedAt)
+	time.Sleep(5 * time.Millisecond)
+
+	report, err := outbox.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxReport{Resolved: 1}, *report)
+	assert.Equal(t, 1, pays)
+
+	record, err = store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, record.Status)
+}
+
+func TestOutboxRecoverWaitsForFreshIntents(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		t.Fatalf("unexpected call to %s", path)
+		return nil, nil
+	})
+
+	ctx := context.Background()
+	outbox, err := NewOutbox(&VTService{client: mockClient, store: NewMemoryTransactionStore()}, OutboxOptions{StaleAfter: time.Hour})
+	require.NoError(t, err)
+
+	_, err = outbox.Enqueue(ctx, PurchaseRequest{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	require.NoError(t, err)
+
+	report, err := outbox.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxReport{}, *report)
+}
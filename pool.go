@@ -0,0 +1,230 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrUnknownAccount = errors.New("unknown account")
+
+// ClientPool holds several VTPass merchant accounts and routes purchases
+// between them by tenant key or serviceID.
+type ClientPool struct {
+	mu             sync.RWMutex
+	accounts       map[string]*VTService
+	order          []string
+	tenants        map[string]string
+	services       map[string]string
+	failover       map[string][]string
+	defaultAccount string
+}
+
+// PoolPurchase is the result of a purchase routed through a ClientPool.
+type PoolPurchase struct {
+	// Account is the name of the account that was debited.
+	Account string
+	// RequestID is the request ID of the purchase that account made. It
+	// differs from the requested one after a failover.
+	RequestID string
+	Response  *PayResponse
+}
+
+// PoolBalance aggregates the wallet balance of every account in a pool.
+type PoolBalance struct {
+	Total    Money
+	Accounts map[string]Money
+	// Errors holds accounts whose balance could not be fetched.
+	Errors map[string]error
+}
+
+func NewClientPool() *ClientPool {
+	return &ClientPool{
+		accounts: make(map[string]*VTService),
+		tenants:  make(map[string]string),
+		services: make(map[string]string),
+		failover: make(map[string][]string),
+	}
+}
+
+// Add registers an account under name. The first account added is the
+// default route.
+func (p *ClientPool) Add(name string, service *VTService) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.accounts[name]; !ok {
+		p.order = append(p.order, name)
+	}
+	p.accounts[name] = service
+	if p.defaultAccount == "" {
+		p.defaultAccount = name
+	}
+}
+
+// Account returns the service registered under name.
+func (p *ClientPool) Account(name string) (*VTService, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	service, ok := p.accounts[name]
+	return service, ok
+}
+
+// SetDefault makes account the route for requests that match no rule.
+func (p *ClientPool) SetDefault(account string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaultAccount = account
+}
+
+// RouteTenant sends every purchase for tenant to account.
+func (p *ClientPool) RouteTenant(tenant, account string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tenants[tenant] = account
+}
+
+// RouteService sends purchases for serviceID to account unless a tenant
+// route applies.
+func (p *ClientPool) RouteService(serviceID, account string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.services[serviceID] = account
+}
+
+// SetFailover lists the accounts tried, in order, when account reports a
+// low wallet balance.
+func (p *ClientPool) SetFailover(account string, secondaries ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failover[account] = secondaries
+}
+
+// Resolve returns the account for a tenant and serviceID. Tenant routes
+// take precedence over serviceID routes, which take precedence over the
+// default account.
+func (p *ClientPool) Resolve(tenant, serviceID string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.resolve(tenant, serviceID)
+}
+
+func (p *ClientPool) resolve(tenant, serviceID string) (string, error) {
+	account, ok := p.tenants[tenant]
+	if !ok {
+		account, ok = p.services[serviceID]
+	}
+	if !ok {
+		account = p.defaultAccount
+	}
+	if _, ok := p.accounts[account]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccount, account)
+	}
+	return account, nil
+}
+
+// Purchase pays through the account routed for tenant and the request's
+// serviceID. If that account returns a low wallet balance (018), its
+// failover accounts are tried in order. Each failover attempt is a new
+// purchase with a fresh request ID, and an idempotency key suffixed with
+// the account name, so that accounts sharing a TransactionStore keep a
+// record per attempt instead of overwriting the refused one.
+func (p *ClientPool) Purchase(ctx context.Context, tenant string, req PurchaseRequest) (*PoolPurchase, error) {
+	p.mu.RLock()
+	primary, err := p.resolve(tenant, req.ServiceID)
+	if err != nil {
+		p.mu.RUnlock()
+		return nil, err
+	}
+	candidates := append([]string{primary}, p.failover[primary]...)
+	services := make([]*VTService, len(candidates))
+	for i, name := range candidates {
+		services[i] = p.accounts[name]
+	}
+	p.mu.RUnlock()
+
+	var lastErr error
+	for i, service := range services {
+		if service == nil {
+			lastErr = fmt.Errorf("%w: %q", ErrUnknownAccount, candidates[i])
+			continue
+		}
+
+		attempt := req
+		if i > 0 {
+			attempt.RequestID = ""
+			if attempt.IdempotencyKey != "" {
+				attempt.IdempotencyKey += "@" + candidates[i]
+			}
+		}
+		if attempt.RequestID == "" {
+			attempt.RequestID = service.GenerateRequestID()
+		}
+
+		resp, err := service.Purchase(ctx, attempt)
+		if err == nil {
+			return &PoolPurchase{Account: candidates[i], RequestID: attempt.RequestID, Response: resp}, nil
+		}
+		if !IsLowBalance(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// Balance fetches the balance of every account. Accounts that fail are
+// reported in Errors and left out of Total; an error is returned only when
+// every account fails.
+func (p *ClientPool) Balance(ctx context.Context) (*PoolBalance, error) {
+	p.mu.RLock()
+	names := append([]string(nil), p.order...)
+	services := make([]*VTService, len(names))
+	for i, name := range names {
+		services[i] = p.accounts[name]
+	}
+	p.mu.RUnlock()
+
+	type result struct {
+		name    string
+		balance Money
+		err     error
+	}
+
+	results := make(chan result, len(names))
+	for i := range names {
+		go func(name string, service *VTService) {
+			wallet, err := service.Balance(ctx)
+			if err != nil {
+				results <- result{name: name, err: err}
+				return
+			}
+			balance, err := ParseMoney(wallet.Contents.Balance)
+			results <- result{name: name, balance: balance, err: err}
+		}(names[i], services[i])
+	}
+
+	total := &PoolBalance{
+		Accounts: make(map[string]Money),
+		Errors:   make(map[string]error),
+	}
+	var lastErr error
+	for range names {
+		r := <-results
+		if r.err != nil {
+			total.Errors[r.name] = r.err
+			lastErr = r.err
+			continue
+		}
+		total.Accounts[r.name] = r.balance
+		total.Total += r.balance
+	}
+
+	if len(names) > 0 && len(total.Accounts) == 0 {
+		return total, lastErr
+	}
+	return total, nil
+}
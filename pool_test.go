@@ -0,0 +1,94 @@
+package vtupass_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockAccount(balance string, payCode string) *VTService {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetGetFunc(func(ctx context.Context, path string) (*http.Response, error) {
+		var wallet WalletBalance
+		wallet.Contents.Balance = balance
+		return jsonResponse(http.StatusOK, wallet), nil
+	})
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		var resp PayResponse
+		resp.Code = payCode
+		if payCode == TRANSACTION_SUCCESSFUL {
+			resp.Content.Transactions.Status = "delivered"
+		}
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+	return &VTService{client: mockClient}
+}
+
+func TestClientPoolRouting(t *testing.T) {
+	pool := NewClientPool()
+	pool.Add("retail", mockAccount("1000.00", TRANSACTION_SUCCESSFUL))
+	pool.Add("corporate", mockAccount("2500.50", LOW_WALLET_BALANCE))
+	pool.Add("reserve", mockAccount("50000", TRANSACTION_SUCCESSFUL))
+
+	pool.RouteTenant("acme", "corporate")
+	pool.RouteService("dstv", "corporate")
+	pool.SetFailover("corporate", "reserve")
+
+	account, err := pool.Resolve("", "mtn")
+	assert.NoError(t, err)
+	assert.Equal(t, "retail", account)
+
+	account, err = pool.Resolve("", "dstv")
+	assert.NoError(t, err)
+	assert.Equal(t, "corporate", account)
+
+	account, err = pool.Resolve("acme", "mtn")
+	assert.NoError(t, err)
+	assert.Equal(t, "corporate", account)
+
+	purchase, err := pool.Purchase(context.Background(), "acme", PurchaseRequest{ServiceID: "mtn", Amount: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, "reserve", purchase.Account)
+
+	pool.SetFailover("corporate")
+	_, err = pool.Purchase(context.Background(), "acme", PurchaseRequest{ServiceID: "mtn", Amount: 100})
+	assert.True(t, IsLowBalance(err))
+
+	balance, err := pool.Balance(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(53500.50), balance.Total)
+	assert.Equal(t, NewMoney(2500.50), balance.Accounts["corporate"])
+}
+
+func TestClientPoolFailoverWithSharedStore(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	corporate := mockAccount("0", LOW_WALLET_BALANCE)
+	corporate.store = store
+	reserve := mockAccount("50000", TRANSACTION_SUCCESSFUL)
+	reserve.store = store
+
+	pool := NewClientPool()
+	pool.Add("corporate", corporate)
+	pool.Add("reserve", reserve)
+	pool.SetFailover("corporate", "reserve")
+
+	ctx := context.Background()
+	purchase, err := pool.Purchase(ctx, "", PurchaseRequest{RequestID: "r1", IdempotencyKey: "order-1", ServiceID: "mtn", Amount: 100})
+	require.NoError(t, err)
+	assert.Equal(t, "reserve", purchase.Account)
+	assert.NotEqual(t, "r1", purchase.RequestID)
+
+	refused, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, refused.Status)
+
+	delivered, err := store.Get(ctx, purchase.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, delivered.Status)
+	assert.Equal(t, "order-1@reserve", delivered.IdempotencyKey)
+}
@@ -0,0 +1,149 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPaymentFailed is returned by PayPostpaidBill when VTPass reports the
+// payment as failed.
+var ErrPaymentFailed = errors.New("payment failed")
+
+// PostpaidBill is the outstanding position of a postpaid meter as reported
+// by verification.
+type PostpaidBill struct {
+	Customer *CustomerInfo
+	// Arrears is the outstanding balance, suggested as the amount to pay.
+	Arrears Money
+}
+
+// PostpaidBillRequest describes a postpaid bill payment. When Amount is
+// zero the outstanding arrears are paid.
+type PostpaidBillRequest struct {
+	// RequestID is generated when empty.
+	RequestID   string
+	Disco       Disco
+	MeterNumber string
+	Amount      float64
+	Phone       string
+}
+
+// PostpaidBillSummary reports how a postpaid payment was split between
+// arrears and the account wallet. The split is only filled in once the
+// payment is delivered.
+type PostpaidBillSummary struct {
+	RequestID        string
+	Status           TransactionStatus
+	Customer         *CustomerInfo
+	SuggestedAmount  Money
+	AmountPaid       Money
+	AppliedToArrears Money
+	AppliedToWallet  Money
+	ArrearsBalance   Money
+	// Estimated is set when the disco did not report the split and it was
+	// derived from the verified arrears instead.
+	Estimated bool
+	Response  *PayResponse
+}
+
+// VerifyPostpaidBill verifies a postpaid meter and returns its arrears. A
+// meter VTPass does not recognise returns an error wrapping
+// ErrCustomerNotFound.
+func (s *VTService) VerifyPostpaidBill(ctx context.Context, meterNumber string, disco Disco) (*PostpaidBill, error) {
+	customer, err := s.VerifyMeterNumber(ctx, meterNumber, MeterTypePostpaid, disco)
+	if err != nil {
+		return nil, err
+	}
+	if customer.WrongBillersCode || customer.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrCustomerNotFound, disco, meterNumber, customer.Error)
+	}
+
+	bill := &PostpaidBill{Customer: customer}
+	if arrears := strings.TrimSpace(customer.CustomerArrears); arrears != "" {
+		bill.Arrears, err = ParseMoney(arrears)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bill, nil
+}
+
+// PayPostpaidBill verifies the meter, pays the requested amount or the
+// outstanding arrears, and summarises where the payment went. A payment
+// VTPass reports as failed returns an error wrapping ErrPaymentFailed; a
+// pending one returns a summary without the split.
+func (s *VTService) PayPostpaidBill(ctx context.Context, req PostpaidBillRequest) (*PostpaidBillSummary, error) {
+	bill, err := s.VerifyPostpaidBill(ctx, req.MeterNumber, req.Disco)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := NewMoney(req.Amount)
+	if amount <= 0 {
+		amount = bill.Arrears
+	}
+	if amount <= 0 {
+		return nil, errors.New("no outstanding arrears; an amount is required")
+	}
+
+	if req.RequestID == "" {
+		req.RequestID = s.GenerateRequestID()
+	}
+
+	resp, err := s.Purchase(ctx, PurchaseRequest{
+		RequestID:     req.RequestID,
+		ServiceID:     string(req.Disco),
+		BillersCode:   req.MeterNumber,
+		VariationCode: string(MeterTypePostpaid),
+		Amount:        amount.Naira(),
+		Phone:         req.Phone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := resp.Status()
+	if status == StatusFailed || status == StatusReversed {
+		return nil, fmt.Errorf("%w: %s: %s %s", ErrPaymentFailed, req.RequestID, resp.Code, resp.ResponseDescription)
+	}
+
+	summary := &PostpaidBillSummary{
+		RequestID:       req.RequestID,
+		Status:          status,
+		Customer:        bill.Customer,
+		SuggestedAmount: bill.Arrears,
+		AmountPaid:      amount,
+		Response:        resp,
+	}
+	if status != StatusDelivered {
+		return summary, nil
+	}
+
+	if resp.AppliedToArrears != nil || resp.AppliedToWallet != nil {
+		if resp.AppliedToArrears != nil {
+			summary.AppliedToArrears = NewMoney(*resp.AppliedToArrears)
+		}
+		if resp.AppliedToWallet != nil {
+			summary.AppliedToWallet = NewMoney(*resp.AppliedToWallet)
+		}
+		if resp.ArrearsBalance != nil {
+			summary.ArrearsBalance = NewMoney(*resp.ArrearsBalance)
+		} else {
+			summary.ArrearsBalance = bill.Arrears - summary.AppliedToArrears
+		}
+		return summary, nil
+	}
+
+	// Without a reported split, discos apply payments to arrears first.
+	summary.Estimated = true
+	summary.AppliedToArrears = min(amount, bill.Arrears)
+	summary.AppliedToWallet = amount - summary.AppliedToArrears
+	summary.ArrearsBalance = bill.Arrears - summary.AppliedToArrears
+	if resp.ArrearsBalance != nil {
+		summary.ArrearsBalance = NewMoney(*resp.ArrearsBalance)
+	}
+
+	return summary, nil
+}
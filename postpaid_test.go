@@ -0,0 +1,100 @@
+package vtupass_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayPostpaidBill(t *testing.T) {
+	var paid PurchaseRequest
+
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		switch path {
+		case "merchant-verify":
+			if payload.(map[string]interface{})["billersCode"] == "1010101010100" {
+				return jsonResponse(http.StatusOK, CustomerInfoResponse{
+					Code:    TRANSACTION_SUCCESSFUL,
+					Content: CustomerInfo{WrongBillersCode: true, Error: "meter not found"},
+				}), nil
+			}
+			return jsonResponse(http.StatusOK, CustomerInfoResponse{
+				Code:    TRANSACTION_SUCCESSFUL,
+				Content: CustomerInfo{CustomerName: "Ada Obi", CustomerArrears: "1,500.00"},
+			}), nil
+		default:
+			paid = payload.(PurchaseRequest)
+			var resp PayResponse
+			switch paid.Phone {
+			case "08000000016":
+				resp.Code = TRANSACTION_FAILED
+				resp.Content.Transactions.Status = "failed"
+			case "08000000099":
+				resp.Code = TRANSACTION_PROCESSING
+				resp.Content.Transactions.Status = "pending"
+			default:
+				resp.Code = TRANSACTION_SUCCESSFUL
+				resp.Content.Transactions.Status = "delivered"
+			}
+			return jsonResponse(http.StatusOK, resp), nil
+		}
+	})
+
+	service := &VTService{client: mockClient}
+
+	summary, err := service.PayPostpaidBill(context.Background(), PostpaidBillRequest{
+		Disco:       DiscoEnugu,
+		MeterNumber: "1010101010101",
+		Phone:       "08011111111",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDelivered, summary.Status)
+	assert.Equal(t, 1500.0, paid.Amount)
+	assert.Equal(t, "postpaid", paid.VariationCode)
+	assert.Equal(t, NewMoney(1500), summary.SuggestedAmount)
+	assert.Equal(t, NewMoney(1500), summary.AppliedToArrears)
+	assert.Equal(t, Money(0), summary.AppliedToWallet)
+	assert.True(t, summary.Estimated)
+
+	summary, err = service.PayPostpaidBill(context.Background(), PostpaidBillRequest{
+		Disco:       DiscoEnugu,
+		MeterNumber: "1010101010101",
+		Amount:      2000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1500), summary.AppliedToArrears)
+	assert.Equal(t, NewMoney(500), summary.AppliedToWallet)
+	assert.Equal(t, Money(0), summary.ArrearsBalance)
+
+	_, err = service.PayPostpaidBill(context.Background(), PostpaidBillRequest{
+		Disco:       DiscoEnugu,
+		MeterNumber: "1010101010101",
+		Phone:       "08000000016",
+	})
+	assert.ErrorIs(t, err, ErrPaymentFailed)
+
+	summary, err = service.PayPostpaidBill(context.Background(), PostpaidBillRequest{
+		Disco:       DiscoEnugu,
+		MeterNumber: "1010101010101",
+		Phone:       "08000000099",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, summary.Status)
+	assert.False(t, summary.Estimated)
+	assert.Zero(t, summary.AppliedToArrears)
+	assert.Zero(t, summary.AppliedToWallet)
+
+	paid = PurchaseRequest{}
+	_, err = service.PayPostpaidBill(context.Background(), PostpaidBillRequest{
+		Disco:       DiscoEnugu,
+		MeterNumber: "1010101010100",
+		Phone:       "08011111111",
+	})
+	assert.ErrorIs(t, err, ErrCustomerNotFound)
+	assert.Empty(t, paid.RequestID)
+}
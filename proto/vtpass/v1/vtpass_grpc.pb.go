@@ -0,0 +1,375 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: vtpass/v1/vtpass.proto
+
+package vtpassv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	VTPassService_GetBalance_FullMethodName       = "/vtpass.v1.VTPassService/GetBalance"
+	VTPassService_ListServices_FullMethodName     = "/vtpass.v1.VTPassService/ListServices"
+	VTPassService_ListVariations_FullMethodName   = "/vtpass.v1.VTPassService/ListVariations"
+	VTPassService_VerifyCustomer_FullMethodName   = "/vtpass.v1.VTPassService/VerifyCustomer"
+	VTPassService_Purchase_FullMethodName         = "/vtpass.v1.VTPassService/Purchase"
+	VTPassService_QueryTransaction_FullMethodName = "/vtpass.v1.VTPassService/QueryTransaction"
+	VTPassService_WatchTransaction_FullMethodName = "/vtpass.v1.VTPassService/WatchTransaction"
+)
+
+// VTPassServiceClient is the client API for VTPassService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VTPassServiceClient interface {
+	// GetBalance returns the wallet balance.
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// ListServices returns the services in a category, e.g. "airtime".
+	ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error)
+	// ListVariations returns the variations (plans, bouquets) of a service.
+	ListVariations(ctx context.Context, in *ListVariationsRequest, opts ...grpc.CallOption) (*ListVariationsResponse, error)
+	// VerifyCustomer looks up a meter or smartcard number.
+	VerifyCustomer(ctx context.Context, in *VerifyCustomerRequest, opts ...grpc.CallOption) (*VerifyCustomerResponse, error)
+	// Purchase pays for a product.
+	Purchase(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error)
+	// QueryTransaction returns the current state of a purchase.
+	QueryTransaction(ctx context.Context, in *QueryTransactionRequest, opts ...grpc.CallOption) (*QueryTransactionResponse, error)
+	// WatchTransaction streams a purchase's state whenever it changes and
+	// ends once the state is final.
+	WatchTransaction(ctx context.Context, in *WatchTransactionRequest, opts ...grpc.CallOption) (VTPassService_WatchTransactionClient, error)
+}
+
+type vTPassServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVTPassServiceClient(cc grpc.ClientConnInterface) VTPassServiceClient {
+	return &vTPassServiceClient{cc}
+}
+
+func (c *vTPassServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, VTPassService_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error) {
+	out := new(ListServicesResponse)
+	err := c.cc.Invoke(ctx, VTPassService_ListServices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) ListVariations(ctx context.Context, in *ListVariationsRequest, opts ...grpc.CallOption) (*ListVariationsResponse, error) {
+	out := new(ListVariationsResponse)
+	err := c.cc.Invoke(ctx, VTPassService_ListVariations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) VerifyCustomer(ctx context.Context, in *VerifyCustomerRequest, opts ...grpc.CallOption) (*VerifyCustomerResponse, error) {
+	out := new(VerifyCustomerResponse)
+	err := c.cc.Invoke(ctx, VTPassService_VerifyCustomer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) Purchase(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error) {
+	out := new(PurchaseResponse)
+	err := c.cc.Invoke(ctx, VTPassService_Purchase_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) QueryTransaction(ctx context.Context, in *QueryTransactionRequest, opts ...grpc.CallOption) (*QueryTransactionResponse, error) {
+	out := new(QueryTransactionResponse)
+	err := c.cc.Invoke(ctx, VTPassService_QueryTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTPassServiceClient) WatchTransaction(ctx context.Context, in *WatchTransactionRequest, opts ...grpc.CallOption) (VTPassService_WatchTransactionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VTPassService_ServiceDesc.Streams[0], VTPassService_WatchTransaction_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vTPassServiceWatchTransactionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VTPassService_WatchTransactionClient interface {
+	Recv() (*TransactionEvent, error)
+	grpc.ClientStream
+}
+
+type vTPassServiceWatchTransactionClient struct {
+	grpc.ClientStream
+}
+
+func (x *vTPassServiceWatchTransactionClient) Recv() (*TransactionEvent, error) {
+	m := new(TransactionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VTPassServiceServer is the server API for VTPassService service.
+// All implementations must embed UnimplementedVTPassServiceServer
+// for forward compatibility
+type VTPassServiceServer interface {
+	// GetBalance returns the wallet balance.
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// ListServices returns the services in a category, e.g. "airtime".
+	ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error)
+	// ListVariations returns the variations (plans, bouquets) of a service.
+	ListVariations(context.Context, *ListVariationsRequest) (*ListVariationsResponse, error)
+	// VerifyCustomer looks up a meter or smartcard number.
+	VerifyCustomer(context.Context, *VerifyCustomerRequest) (*VerifyCustomerResponse, error)
+	// Purchase pays for a product.
+	Purchase(context.Context, *PurchaseRequest) (*PurchaseResponse, error)
+	// QueryTransaction returns the current state of a purchase.
+	QueryTransaction(context.Context, *QueryTransactionRequest) (*QueryTransactionResponse, error)
+	// WatchTransaction streams a purchase's state whenever it changes and
+	// ends once the state is final.
+	WatchTransaction(*WatchTransactionRequest, VTPassService_WatchTransactionServer) error
+	mustEmbedUnimplementedVTPassServiceServer()
+}
+
+// UnimplementedVTPassServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedVTPassServiceServer struct {
+}
+
+func (UnimplementedVTPassServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedVTPassServiceServer) ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListServices not implemented")
+}
+func (UnimplementedVTPassServiceServer) ListVariations(context.Context, *ListVariationsRequest) (*ListVariationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVariations not implemented")
+}
+func (UnimplementedVTPassServiceServer) VerifyCustomer(context.Context, *VerifyCustomerRequest) (*VerifyCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyCustomer not implemented")
+}
+func (UnimplementedVTPassServiceServer) Purchase(context.Context, *PurchaseRequest) (*PurchaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purchase not implemented")
+}
+func (UnimplementedVTPassServiceServer) QueryTransaction(context.Context, *QueryTransactionRequest) (*QueryTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryTransaction not implemented")
+}
+func (UnimplementedVTPassServiceServer) WatchTransaction(*WatchTransactionRequest, VTPassService_WatchTransactionServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTransaction not implemented")
+}
+func (UnimplementedVTPassServiceServer) mustEmbedUnimplementedVTPassServiceServer() {}
+
+// UnsafeVTPassServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VTPassServiceServer will
+// result in compilation errors.
+type UnsafeVTPassServiceServer interface {
+	mustEmbedUnimplementedVTPassServiceServer()
+}
+
+func RegisterVTPassServiceServer(s grpc.ServiceRegistrar, srv VTPassServiceServer) {
+	s.RegisterService(&VTPassService_ServiceDesc, srv)
+}
+
+func _VTPassService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).ListServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_ListServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).ListServices(ctx, req.(*ListServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_ListVariations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVariationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).ListVariations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_ListVariations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).ListVariations(ctx, req.(*ListVariationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_VerifyCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).VerifyCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_VerifyCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).VerifyCustomer(ctx, req.(*VerifyCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_Purchase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).Purchase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_Purchase_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).Purchase(ctx, req.(*PurchaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_QueryTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTPassServiceServer).QueryTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VTPassService_QueryTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTPassServiceServer).QueryTransaction(ctx, req.(*QueryTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTPassService_WatchTransaction_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTransactionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VTPassServiceServer).WatchTransaction(m, &vTPassServiceWatchTransactionServer{stream})
+}
+
+type VTPassService_WatchTransactionServer interface {
+	Send(*TransactionEvent) error
+	grpc.ServerStream
+}
+
+type vTPassServiceWatchTransactionServer struct {
+	grpc.ServerStream
+}
+
+func (x *vTPassServiceWatchTransactionServer) Send(m *TransactionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// VTPassService_ServiceDesc is the grpc.ServiceDesc for VTPassService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VTPassService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vtpass.v1.VTPassService",
+	HandlerType: (*VTPassServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBalance",
+			Handler:    _VTPassService_GetBalance_Handler,
+		},
+		{
+			MethodName: "ListServices",
+			Handler:    _VTPassService_ListServices_Handler,
+		},
+		{
+			MethodName: "ListVariations",
+			Handler:    _VTPassService_ListVariations_Handler,
+		},
+		{
+			MethodName: "VerifyCustomer",
+			Handler:    _VTPassService_VerifyCustomer_Handler,
+		},
+		{
+			MethodName: "Purchase",
+			Handler:    _VTPassService_Purchase_Handler,
+		},
+		{
+			MethodName: "QueryTransaction",
+			Handler:    _VTPassService_QueryTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTransaction",
+			Handler:       _VTPassService_WatchTransaction_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "vtpass/v1/vtpass.proto",
+}
@@ -0,0 +1,302 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrQuoteExpired      = errors.New("quote expired")
+	ErrQuoteChanged      = errors.New("catalog changed since quote")
+	ErrServiceNotFound   = errors.New("service not found")
+	ErrVariationNotFound = errors.New("variation not found")
+	ErrCustomerNotFound  = errors.New("customer not found")
+)
+
+// DefaultQuoteTTL is how long a quote can be paid when WithQuoteTTL is not
+// used.
+const DefaultQuoteTTL = 2 * time.Minute
+
+// Markup is added to quoted prices on top of VTPass's charges.
+type Markup struct {
+	// Percent is a percentage of the product amount, e.g. 1.5 for 1.5%.
+	Percent float64
+	Flat    Money
+}
+
+func (m Markup) apply(amount Money) Money {
+	return m.Flat + Money(math.Round(float64(amount)*m.Percent/100))
+}
+
+// WithMarkup sets the markup added to every quote.
+func WithMarkup(markup Markup) Option {
+	return func(s *VTService) {
+		s.markup = markup
+	}
+}
+
+// WithServiceMarkup sets the markup for quotes on serviceID, replacing
+// the one set with WithMarkup.
+func WithServiceMarkup(serviceID string, markup Markup) Option {
+	return func(s *VTService) {
+		if s.serviceMarkups == nil {
+			s.serviceMarkups = make(map[string]Markup)
+		}
+		s.serviceMarkups[serviceID] = markup
+	}
+}
+
+// WithQuoteTTL sets how long quotes can be paid. Defaults to
+// DefaultQuoteTTL.
+func WithQuoteTTL(ttl time.Duration) Option {
+	return func(s *VTService) {
+		s.quoteTTL = ttl
+	}
+}
+
+// QuoteRequest describes a purchase to price.
+type QuoteRequest struct {
+	PurchaseRequest
+	// Identifier is the service's category, e.g. IdentifierTVSubscription,
+	// used to look up its convenience fee. When empty every category is
+	// searched, and the quote keeps the category the service was found in.
+	Identifier string
+}
+
+// Quote is the price of a purchase, valid until ExpiresAt.
+type Quote struct {
+	// Request is the purchase PurchaseFromQuote makes, with the request ID
+	// and amount filled in.
+	Request QuoteRequest
+	// Customer is the verified meter or smartcard owner, when the service
+	// has one.
+	Customer      *CustomerInfo
+	VariationName string
+	// Amount is the product price paid to VTPass.
+	Amount         Money
+	ConvenienceFee Money
+	Markup         Money
+	// Total is what to charge the customer.
+	Total     Money
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the quote can no longer be paid.
+func (q Quote) Expired() bool {
+	return !time.Now().Before(q.ExpiresAt)
+}
+
+// QuotePurchase prices req without paying for it. It verifies the
+// customer for electricity and TV services, resolves the variation's
+// fixed price, and adds the service's convenience fee and the configured
+// markup.
+func (s *VTService) QuotePurchase(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	customer, err := s.VerifyPurchaseCustomer(ctx, req.PurchaseRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.price(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	quote.Customer = customer
+	if quote.Request.RequestID == "" {
+		quote.Request.RequestID = s.GenerateRequestID()
+	}
+
+	ttl := s.quoteTTL
+	if ttl <= 0 {
+		ttl = DefaultQuoteTTL
+	}
+	quote.ExpiresAt = time.Now().Add(ttl)
+	return quote, nil
+}
+
+// PurchaseFromQuote pays for quote at the quoted amount. It fails with
+// ErrQuoteExpired after the quote's expiry and with ErrQuoteChanged when
+// the customer, variation price or convenience fee has changed since.
+// Paying the same quote twice reuses its request ID.
+func (s *VTService) PurchaseFromQuote(ctx context.Context, quote *Quote) (*PayResponse, error) {
+	if quote == nil {
+		return nil, errors.New("quote is required")
+	}
+	if err := s.checkLive(); err != nil {
+		return nil, err
+	}
+	if quote.Expired() {
+		return nil, ErrQuoteExpired
+	}
+
+	customer, err := s.VerifyPurchaseCustomer(ctx, quote.Request.PurchaseRequest)
+	if errors.Is(err, ErrCustomerNotFound) {
+		return nil, fmt.Errorf("%w: %v", ErrQuoteChanged, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if quote.Customer != nil && customer != nil && customer.CustomerName != quote.Customer.CustomerName {
+		return nil, fmt.Errorf("%w: customer %q is now %q", ErrQuoteChanged, quote.Customer.CustomerName, customer.CustomerName)
+	}
+
+	current, err := s.price(ctx, quote.Request)
+	if errors.Is(err, ErrVariationNotFound) || errors.Is(err, ErrServiceNotFound) {
+		return nil, fmt.Errorf("%w: %v", ErrQuoteChanged, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.Amount != quote.Amount {
+		return nil, fmt.Errorf("%w: amount %s is now %s", ErrQuoteChanged, quote.Amount, current.Amount)
+	}
+	if current.ConvenienceFee != quote.ConvenienceFee {
+		return nil, fmt.Errorf("%w: convenience fee %s is now %s", ErrQuoteChanged, quote.ConvenienceFee, current.ConvenienceFee)
+	}
+
+	return s.Purchase(ctx, quote.Request.PurchaseRequest)
+}
+
+// price resolves the amount and charges of req from the current catalog.
+func (s *VTService) price(ctx context.Context, req QuoteRequest) (*Quote, error) {
+	quote := &Quote{Request: req}
+	amount := NewMoney(req.Amount)
+
+	if _, err := ParseDisco(req.ServiceID); err != nil && req.VariationCode != "" {
+		variation, err := s.findVariation(ctx, req.ServiceID, req.VariationCode)
+		if err != nil {
+			return nil, err
+		}
+		quote.VariationName = variation.Name
+		if strings.EqualFold(variation.FixedPrice, "Yes") || amount == 0 {
+			amount = NewMoney(variation.VariationAmount.Float64())
+		}
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("no amount for %s %s", req.ServiceID, req.VariationCode)
+	}
+
+	service, identifier, err := s.findService(ctx, req.Identifier, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	// Later repricing, e.g. by PurchaseFromQuote, looks in this category only.
+	quote.Request.Identifier = identifier
+	fee, err := convenienceFee(service.ConvenienceFee.String(), amount)
+	if err != nil {
+		return nil, err
+	}
+
+	markup := s.markup
+	if m, ok := s.serviceMarkups[req.ServiceID]; ok {
+		markup = m
+	}
+
+	quote.Request.Amount = amount.Naira()
+	quote.Amount = amount
+	quote.ConvenienceFee = fee
+	quote.Markup = markup.apply(amount)
+	quote.Total = amount + fee + quote.Markup
+	return quote, nil
+}
+
+// tvServices are the services whose smartcard numbers can be verified.
+var tvServices = map[string]bool{"dstv": true, "gotv": true, "startimes": true}
+
+// IsTVService reports whether serviceID is a TV subscription whose
+// smartcard can be verified.
+func IsTVService(serviceID string) bool {
+	return tvServices[serviceID]
+}
+
+// VerifyPurchaseCustomer verifies the meter or smartcard req pays for. It
+// returns nil without an error when req has no BillersCode or its service
+// has no customer to verify. A customer VTPass does not recognise returns
+// the CustomerInfo with an error wrapping ErrCustomerNotFound.
+func (s *VTService) VerifyPurchaseCustomer(ctx context.Context, req PurchaseRequest) (*CustomerInfo, error) {
+	if req.BillersCode == "" {
+		return nil, nil
+	}
+
+	var (
+		info *CustomerInfo
+		err  error
+	)
+	if disco, parseErr := ParseDisco(req.ServiceID); parseErr == nil {
+		info, err = s.VerifyMeterNumber(ctx, req.BillersCode, MeterType(req.VariationCode), disco)
+	} else if tvServices[req.ServiceID] {
+		info, err = s.VerifyCustomer(ctx, req.ServiceID, req.BillersCode, "")
+	} else {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.WrongBillersCode || info.Error != "" {
+		return info, fmt.Errorf("%w: %s %s: %s", ErrCustomerNotFound, req.ServiceID, req.BillersCode, info.Error)
+	}
+	return info, nil
+}
+
+func (s *VTService) findVariation(ctx context.Context, serviceID, code string) (*Variation, error) {
+	variations, err := s.ServiceVariations(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variations {
+		if variations[i].VariationCode == code {
+			return &variations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrVariationNotFound, serviceID, code)
+}
+
+// findService returns serviceID and the identifier of the category it
+// was found in, searching every category when identifier is empty.
+func (s *VTService) findService(ctx context.Context, identifier, serviceID string) (*Service, string, error) {
+	identifiers := []string{identifier}
+	if identifier == "" {
+		categories, err := s.ServiceCategories(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		identifiers = identifiers[:0]
+		for _, category := range categories {
+			identifiers = append(identifiers, category.Identifier)
+		}
+	}
+
+	for _, id := range identifiers {
+		services, err := s.ServiceByIdentifier(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range services {
+			if services[i].ServiceID == serviceID {
+				return &services[i], id, nil
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+}
+
+// convenienceFee parses a service's fee, which VTPass gives either as an
+// amount such as "N100" or as a percentage of amount such as "1.5 %".
+func convenienceFee(fee string, amount Money) (Money, error) {
+	fee = strings.TrimSpace(fee)
+	if fee == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(fee, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(fee, "%")), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid convenience fee %q: %w", fee, err)
+		}
+		return Money(math.Round(float64(amount) * percent / 100)), nil
+	}
+	return ParseMoney(fee)
+}
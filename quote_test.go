@@ -0,0 +1,125 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvenienceFee(t *testing.T) {
+	fee, err := convenienceFee("N100.00", NewMoney(5000))
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(100), fee)
+
+	fee, err = convenienceFee("1.5 %", NewMoney(5000))
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(75), fee)
+
+	fee, err = convenienceFee("", NewMoney(5000))
+	require.NoError(t, err)
+	assert.Zero(t, fee)
+
+	_, err = convenienceFee("free", NewMoney(5000))
+	assert.Error(t, err)
+}
+
+func TestQuoteAndPurchase(t *testing.T) {
+	price := "4615.00"
+	customer := "ADA OBI"
+	calls := map[string]int{}
+	var paid map[string]interface{}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		endpoint := strings.TrimPrefix(req.URL.Path, "/api/")
+		if req.URL.RawQuery != "" {
+			endpoint += "?" + req.URL.RawQuery
+		}
+		calls[endpoint]++
+		switch endpoint {
+		case "merchant-verify":
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"Customer_Name":"`+customer+`","Current_Bouquet":"Compact"}}`), nil
+		case "service-categories":
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":[{"identifier":"airtime"},{"identifier":"tv-subscription"}]}`), nil
+		case "services?identifier=airtime":
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":[{"serviceID":"mtn","convinience_fee":"0 %"}]}`), nil
+		case "services?identifier=tv-subscription":
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":[{"serviceID":"gotv","convinience_fee":"N50"}]}`), nil
+		case "service-variations?serviceID=gotv":
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"varations":[{"variation_code":"gotv-max","name":"GOtv Max","variation_amount":"`+price+`","fixedPrice":"Yes"}]}}`), nil
+		case "pay":
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &paid))
+			return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`), nil
+		}
+		return rawJSONResponse(http.StatusNotFound, `{}`), nil
+	})
+	service := NewVTService("key", "pk", "sk", EnvironmentSandbox,
+		WithClientOptions(httpclient.WithHTTPClient(&http.Client{Transport: transport})),
+		WithMarkup(Markup{Flat: NewMoney(10)}),
+		WithServiceMarkup("gotv", Markup{Percent: 1}))
+	ctx := context.Background()
+
+	req := QuoteRequest{PurchaseRequest: PurchaseRequest{
+		ServiceID: "gotv", BillersCode: "1212121212", VariationCode: "gotv-max", Amount: 1, Phone: "08011111111",
+	}}
+	quote, err := service.QuotePurchase(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "ADA OBI", quote.Customer.CustomerName)
+	assert.Equal(t, "GOtv Max", quote.VariationName)
+	assert.Equal(t, NewMoney(4615), quote.Amount)
+	assert.Equal(t, NewMoney(50), quote.ConvenienceFee)
+	assert.Equal(t, NewMoney(46.15), quote.Markup)
+	assert.Equal(t, NewMoney(4711.15), quote.Total)
+	assert.NotEmpty(t, quote.Request.RequestID)
+	assert.Equal(t, IdentifierTVSubscription, quote.Request.Identifier)
+	assert.WithinDuration(t, time.Now().Add(DefaultQuoteTTL), quote.ExpiresAt, time.Second)
+
+	_, err = service.PurchaseFromQuote(ctx, quote)
+	require.NoError(t, err)
+	assert.Equal(t, 4615.0, paid["amount"])
+	assert.Equal(t, quote.Request.RequestID, paid["request_id"])
+	// The customer is verified again, and the service is looked up in the
+	// category resolved by the quote only.
+	assert.Equal(t, 2, calls["merchant-verify"])
+	assert.Equal(t, 1, calls["service-categories"])
+	assert.Equal(t, 1, calls["services?identifier=airtime"])
+
+	customer = "EMEKA OBI"
+	paid = nil
+	_, err = service.PurchaseFromQuote(ctx, quote)
+	assert.ErrorIs(t, err, ErrQuoteChanged)
+	assert.Nil(t, paid)
+	customer = "ADA OBI"
+
+	price = "4850.00"
+	paid = nil
+	_, err = service.PurchaseFromQuote(ctx, quote)
+	assert.ErrorIs(t, err, ErrQuoteChanged)
+	assert.Nil(t, paid)
+
+	quote.ExpiresAt = time.Now().Add(-time.Second)
+	_, err = service.PurchaseFromQuote(ctx, quote)
+	assert.ErrorIs(t, err, ErrQuoteExpired)
+
+	_, err = service.PurchaseFromQuote(ctx, nil)
+	assert.Error(t, err)
+
+	airtime, err := service.QuotePurchase(ctx, QuoteRequest{
+		PurchaseRequest: PurchaseRequest{ServiceID: "mtn", Amount: 100, Phone: "08011111111"},
+		Identifier:      IdentifierAirtime,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, airtime.Customer)
+	assert.Equal(t, NewMoney(110), airtime.Total)
+
+	_, err = service.QuotePurchase(ctx, QuoteRequest{PurchaseRequest: PurchaseRequest{ServiceID: "gotv", VariationCode: "gotv-lite"}})
+	assert.ErrorIs(t, err, ErrVariationNotFound)
+}
@@ -0,0 +1,100 @@
+package vtupass_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPError is returned for a non-2xx response without a VTPass code, such
+// as an HTML error page from a gateway in front of VTPass. Unlike an
+// ErrorResponse it does not mean the request was refused: a purchase may
+// have gone through and should be requeried.
+type HTTPError struct {
+	StatusCode int
+	Body       json.RawMessage
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// RawResponse receives the undecoded response of a call made with a
+// context from WithRawResponse.
+type RawResponse struct {
+	HTTPStatus int
+	Header     http.Header
+	Body       json.RawMessage
+
+	// parent is the caller's RawResponse when the package captures a
+	// response for itself.
+	parent *RawResponse
+}
+
+type rawResponseKey struct{}
+
+// WithRawResponse returns a context that makes service calls copy VTPass's
+// response into raw, alongside the typed result. It is useful for auditing
+// and for reading fields this package does not decode yet. When a call
+// retries, raw holds the last response.
+func WithRawResponse(ctx context.Context, raw *RawResponse) context.Context {
+	return context.WithValue(ctx, rawResponseKey{}, raw)
+}
+
+// withRawCapture returns a context capturing responses into a new
+// RawResponse as well as into any the caller installed.
+func withRawCapture(ctx context.Context) (context.Context, *RawResponse) {
+	raw := &RawResponse{}
+	raw.parent, _ = ctx.Value(rawResponseKey{}).(*RawResponse)
+	return WithRawResponse(ctx, raw), raw
+}
+
+func captureRawResponse(ctx context.Context, resp *http.Response, body []byte) {
+	raw, _ := ctx.Value(rawResponseKey{}).(*RawResponse)
+	for ; raw != nil; raw = raw.parent {
+		raw.HTTPStatus = resp.StatusCode
+		raw.Header = resp.Header
+		raw.Body = append(json.RawMessage(nil), body...)
+	}
+}
+
+// bufferBody reads resp's body and replaces it with an in-memory copy so
+// that it can be read again.
+func bufferBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// rawRecorder is implemented by response types that keep the body and
+// status they were decoded from.
+type rawRecorder interface {
+	setRaw(status int, body []byte)
+}
+
+func (r *ResponseMeta) setRaw(status int, body []byte) {
+	r.HTTPStatus = status
+	r.Raw = body
+}
+
+func (r *PayResponse) setRaw(status int, body []byte) {
+	r.HTTPStatus = status
+	r.Raw = body
+}
+
+func (r *TransactionResponse) setRaw(status int, body []byte) {
+	r.HTTPStatus = status
+	r.Raw = body
+}
+
+func (r *CustomerInfoResponse) setRaw(status int, body []byte) {
+	r.HTTPStatus = status
+	r.Raw = body
+}
@@ -0,0 +1,89 @@
+package vtupass_go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rawJSONResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestResponsesKeepRawBody(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetGetFunc(func(ctx context.Context, path string) (*http.Response, error) {
+		if path == "balance" {
+			return rawJSONResponse(http.StatusOK, `{"code":"000","contents":{"balance":"100.00"},"cashback":"2.50"}`), nil
+		}
+		return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"ServiceName":"MTN Data","varations":[],"new_field":true}}`), nil
+	})
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return rawJSONResponse(http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered","commission":3.5}}}`), nil
+	})
+	service := &VTService{client: mockClient}
+	ctx := context.Background()
+
+	balance, err := service.Balance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, balance.HTTPStatus)
+	assert.Contains(t, string(balance.Raw), `"cashback":"2.50"`)
+
+	pay, err := service.Purchase(ctx, PurchaseRequest{ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, pay.HTTPStatus)
+	assert.Contains(t, string(pay.Raw), `"commission":3.5`)
+
+	var raw RawResponse
+	_, err = service.ServiceVariations(WithRawResponse(ctx, &raw), "mtn-data")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, raw.HTTPStatus)
+	assert.Equal(t, "application/json", raw.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"code":"000","content":{"ServiceName":"MTN Data","varations":[],"new_field":true}}`, string(raw.Body))
+}
+
+func TestErrorResponseKeepsRawBody(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return rawJSONResponse(http.StatusBadRequest, `{"code":"011","response_description":"INVALID ARGUMENTS"}`), nil
+	})
+	service := &VTService{client: mockClient}
+
+	var raw RawResponse
+	_, err := service.Purchase(WithRawResponse(context.Background(), &raw), PurchaseRequest{ServiceID: "mtn", Phone: "08011111111"})
+	var errorResponse ErrorResponse
+	require.ErrorAs(t, err, &errorResponse)
+	assert.Equal(t, INVALID_ARGUMENTS, errorResponse.Code)
+	assert.True(t, err == ErrorResponse{BaseResponse{Code: INVALID_ARGUMENTS}})
+	assert.ErrorIs(t, err, ErrorResponse{BaseResponse{Code: INVALID_ARGUMENTS}})
+	assert.Equal(t, http.StatusBadRequest, raw.HTTPStatus)
+	assert.Contains(t, string(raw.Body), "INVALID ARGUMENTS")
+}
+
+func TestHTTPErrorWithoutCode(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetGetFunc(func(ctx context.Context, path string) (*http.Response, error) {
+		return rawJSONResponse(http.StatusBadGateway, `<html>Bad Gateway</html>`), nil
+	})
+	service := &VTService{client: mockClient}
+
+	_, err := service.Balance(context.Background())
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	assert.Contains(t, string(httpErr.Body), "Bad Gateway")
+	assert.EqualError(t, err, "HTTP 502")
+	assert.False(t, errors.As(err, new(ErrorResponse)))
+}
@@ -0,0 +1,237 @@
+package vtupass_go
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidToken is returned when a purchase carries a token that does not
+// normalise to the 20 digits of an STS token.
+var ErrInvalidToken = errors.New("token is not a 20 digit STS token")
+
+// ElectricityReceipt is the normalised result of an electricity purchase.
+// Discos report tokens, units and deductions in different shapes; the
+// parser folds them into one set of fields.
+type ElectricityReceipt struct {
+	RequestID     string
+	TransactionID string
+	ProductName   string
+	MeterNumber   string
+	CustomerName  string
+	Address       string
+
+	// Token is formatted as five groups of four digits, e.g.
+	// "1234-5678-9012-3456-7890". Postpaid purchases have no token.
+	Token  string
+	Units  float64
+	Tariff string
+
+	Amount         Money
+	VAT            Money
+	DebtDeducted   Money
+	ArrearsBalance Money
+
+	// KCT1 and KCT2 are key change tokens issued when a meter's keys were
+	// rotated. They must be loaded before Token.
+	KCT1 string
+	KCT2 string
+
+	InvoiceNumber     string
+	ExchangeReference string
+}
+
+var (
+	tokenLabel    = regexp.MustCompile(`(?i)^\s*token\s*[:=]?\s*`)
+	tokenPattern  = regexp.MustCompile(`(?i)\btoken\s*[:=]?\s*(\d(?:[\s-]?\d){19})\b`)
+	kct1Pattern   = regexp.MustCompile(`(?i)\bkct\s*1\s*[:=]?\s*(\d(?:[\s-]?\d){19})\b`)
+	kct2Pattern   = regexp.MustCompile(`(?i)\bkct\s*2\s*[:=]?\s*(\d(?:[\s-]?\d){19})\b`)
+	unitsPattern  = regexp.MustCompile(`(?i)\bunits?\s*[:=]?\s*([\d][\d,]*(?:\.\d+)?)`)
+	kwhPattern    = regexp.MustCompile(`(?i)([\d][\d,]*(?:\.\d+)?)\s*kwh`)
+	tariffPattern = regexp.MustCompile(`(?i)\btariff(?:\s*(?:class|code|rate))?\s*[:=]\s*([^,;|()]+)`)
+	vatPattern    = regexp.MustCompile(`(?i)\bvat\s*[:=]\s*(?:₦|NGN|N)?\s*([\d][\d,]*(?:\.\d+)?)`)
+	debtPattern   = regexp.MustCompile(`(?i)\b(?:debt|arrears)(?:\s*(?:amount|deducted|paid))?\s*[:=]\s*(?:₦|NGN|N)?\s*([\d][\d,]*(?:\.\d+)?)`)
+)
+
+// ParseElectricityReceipt extracts an ElectricityReceipt from a pay
+// response. If a token is present but is not 20 digits, the receipt is
+// returned with the raw token together with ErrInvalidToken.
+func ParseElectricityReceipt(resp *PayResponse) (*ElectricityReceipt, error) {
+	if resp == nil {
+		return nil, errors.New("nil pay response")
+	}
+
+	txn := resp.Content.Transactions
+	receipt := &ElectricityReceipt{
+		RequestID:         resp.RequestID,
+		TransactionID:     txn.TransactionID,
+		ProductName:       txn.ProductName,
+		MeterNumber:       txn.UniqueElement,
+		InvoiceNumber:     resp.InvoiceNumber,
+		ExchangeReference: resp.ExchangeReference,
+	}
+
+	// Structured fields win; the free-text token and purchased code fill
+	// in whatever a disco leaves out.
+	text := strings.Join([]string{resp.Token, resp.PurchasedCode}, " | ")
+
+	rawToken := firstMatch(tokenPattern, text)
+	if rawToken == "" && digitsOnly(resp.Token) != "" {
+		rawToken = tokenLabel.ReplaceAllString(resp.Token, "")
+	}
+	receipt.KCT1 = formatToken(firstMatch(kct1Pattern, text))
+	receipt.KCT2 = formatToken(firstMatch(kct2Pattern, text))
+
+	if units, ok := parseNumber(resp.Units.String()); ok {
+		receipt.Units = units
+	} else if units, ok := parseNumber(firstMatch(unitsPattern, text)); ok {
+		receipt.Units = units
+	} else if units, ok := parseNumber(firstMatch(kwhPattern, text)); ok {
+		receipt.Units = units
+	}
+
+	receipt.Tariff = strings.TrimSpace(firstMatch(tariffPattern, text))
+
+	if vat, err := ParseMoney(resp.VAT.String()); err == nil {
+		receipt.VAT = vat
+	} else if vat, err := ParseMoney(firstMatch(vatPattern, text)); err == nil {
+		receipt.VAT = vat
+	}
+
+	if resp.AppliedToArrears != nil {
+		receipt.DebtDeducted = NewMoney(*resp.AppliedToArrears)
+	} else if debt, err := ParseMoney(firstMatch(debtPattern, text)); err == nil {
+		receipt.DebtDeducted = debt
+	}
+	if resp.ArrearsBalance != nil {
+		receipt.ArrearsBalance = NewMoney(*resp.ArrearsBalance)
+	}
+
+	if resp.Amount > 0 {
+		receipt.Amount = NewMoney(resp.Amount.Float64())
+	} else if txn.TotalAmount > 0 {
+		receipt.Amount = NewMoney(txn.TotalAmount.Float64())
+	}
+
+	if rawToken == "" {
+		return receipt, nil
+	}
+	receipt.Token = formatToken(rawToken)
+	if len(digitsOnly(rawToken)) != 20 {
+		return receipt, ErrInvalidToken
+	}
+	return receipt, nil
+}
+
+// WithCustomer fills in the customer details and key change tokens returned
+// by VerifyMeterNumber.
+func (r *ElectricityReceipt) WithCustomer(info *CustomerInfo) *ElectricityReceipt {
+	if info == nil {
+		return r
+	}
+	if r.CustomerName == "" {
+		r.CustomerName = info.CustomerName
+	}
+	if r.Address == "" {
+		r.Address = info.Address
+	}
+	if r.MeterNumber == "" {
+		r.MeterNumber = info.MeterNumber
+	}
+	if r.KCT1 == "" {
+		r.KCT1 = formatToken(info.KCT1)
+	}
+	if r.KCT2 == "" {
+		r.KCT2 = formatToken(info.KCT2)
+	}
+	return r
+}
+
+// Render returns a plain-text receipt suitable for showing to a customer.
+func (r *ElectricityReceipt) Render() string {
+	var b strings.Builder
+
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%-16s %s\n", label+":", value)
+		}
+	}
+	money := func(label string, m Money) {
+		if m != 0 {
+			line(label, "NGN "+m.String())
+		}
+	}
+
+	b.WriteString("ELECTRICITY PURCHASE RECEIPT\n")
+	line("Product", r.ProductName)
+	line("Customer", r.CustomerName)
+	line("Address", r.Address)
+	line("Meter Number", r.MeterNumber)
+	if r.KCT1 != "" || r.KCT2 != "" {
+		line("KCT1", r.KCT1)
+		line("KCT2", r.KCT2)
+	}
+	line("Token", r.Token)
+	if r.Units > 0 {
+		line("Units", strconv.FormatFloat(r.Units, 'f', -1, 64)+" kWh")
+	}
+	line("Tariff", r.Tariff)
+	money("Amount", r.Amount)
+	money("VAT", r.VAT)
+	money("Debt Deducted", r.DebtDeducted)
+	money("Arrears Balance", r.ArrearsBalance)
+	line("Invoice Number", r.InvoiceNumber)
+	line("Reference", r.ExchangeReference)
+	line("Transaction ID", r.TransactionID)
+	line("Request ID", r.RequestID)
+	if r.KCT1 != "" || r.KCT2 != "" {
+		b.WriteString("Load KCT1 and KCT2 before the token.\n")
+	}
+
+	return b.String()
+}
+
+func firstMatch(pattern *regexp.Regexp, text string) string {
+	if m := pattern.FindStringSubmatch(text); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// formatToken groups a 20 digit token into blocks of four. Anything else is
+// returned trimmed but otherwise untouched.
+func formatToken(raw string) string {
+	digits := digitsOnly(raw)
+	if len(digits) != 20 {
+		return strings.TrimSpace(raw)
+	}
+
+	groups := make([]string, 0, 5)
+	for i := 0; i < len(digits); i += 4 {
+		groups = append(groups, digits[i:i+4])
+	}
+	return strings.Join(groups, "-")
+}
+
+func parseNumber(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "kWh"), "KWH")
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil
+}
@@ -0,0 +1,97 @@
+package vtupass_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseElectricityReceipt(t *testing.T) {
+	arrears := 150.0
+
+	testCases := []struct {
+		name     string
+		response PayResponse
+		expected ElectricityReceipt
+		err      error
+	}{
+		{
+			name: "Dashed Token",
+			response: PayResponse{
+				Token: "Token : 1234-5678-9012-3456-7890",
+				Units: "30 kWh",
+				VAT:   "37.50",
+			},
+			expected: ElectricityReceipt{Token: "1234-5678-9012-3456-7890", Units: 30, VAT: 3750},
+		},
+		{
+			name: "Token With Units In Text",
+			response: PayResponse{
+				PurchasedCode: "Token : 12345678901234567890 (Units: 30.5)",
+				Amount:        1000,
+			},
+			expected: ElectricityReceipt{Token: "1234-5678-9012-3456-7890", Units: 30.5, Amount: 100000},
+		},
+		{
+			name: "KCT And Deductions",
+			response: PayResponse{
+				PurchasedCode:    "KCT1 : 1111 2222 3333 4444 5555 ; KCT2 : 6666 7777 8888 9999 0000 ; Token : 1234 5678 9012 3456 7890 ; Tariff Class: R2 ; VAT: N12.30",
+				AppliedToArrears: &arrears,
+			},
+			expected: ElectricityReceipt{
+				Token:        "1234-5678-9012-3456-7890",
+				KCT1:         "1111-2222-3333-4444-5555",
+				KCT2:         "6666-7777-8888-9999-0000",
+				Tariff:       "R2",
+				VAT:          1230,
+				DebtDeducted: 15000,
+			},
+		},
+		{
+			name: "Debt In Text",
+			response: PayResponse{
+				Token:         "Token: 12345678901234567890",
+				PurchasedCode: "Debt Amount: 2,000 | 45 kWh",
+			},
+			expected: ElectricityReceipt{Token: "1234-5678-9012-3456-7890", Units: 45, DebtDeducted: 200000},
+		},
+		{
+			name:     "Postpaid Without Token",
+			response: PayResponse{Amount: 500},
+			expected: ElectricityReceipt{Amount: 50000},
+		},
+		{
+			name:     "Short Token",
+			response: PayResponse{Token: "Token : 1234-5678"},
+			expected: ElectricityReceipt{Token: "1234-5678"},
+			err:      ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receipt, err := ParseElectricityReceipt(&tc.response)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, *receipt)
+		})
+	}
+}
+
+func TestElectricityReceiptRender(t *testing.T) {
+	receipt, err := ParseElectricityReceipt(&PayResponse{
+		RequestID: "202401011200abc",
+		Token:     "Token : 12345678901234567890",
+		Units:     "30",
+		Amount:    1000,
+	})
+	assert.NoError(t, err)
+
+	receipt.WithCustomer(&CustomerInfo{CustomerName: "Ada Obi", MeterNumber: "1111111111111"})
+
+	text := receipt.Render()
+	assert.Contains(t, text, "Token:           1234-5678-9012-3456-7890")
+	assert.Contains(t, text, "Units:           30 kWh")
+	assert.Contains(t, text, "Customer:        Ada Obi")
+	assert.Contains(t, text, "Amount:          NGN 1000.00")
+	assert.NotContains(t, text, "KCT1")
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronNext(t *testing.T) {
+	lagos := time.FixedZone("WAT", 60*60)
+	from := time.Date(2024, 3, 15, 10, 30, 0, 0, lagos) // a Friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 9 1 * *", time.Date(2024, 4, 1, 9, 0, 0, 0, lagos)},
+		{"0 8 * * FRI", time.Date(2024, 3, 22, 8, 0, 0, 0, lagos)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 45, 0, 0, lagos)},
+		{"30 10 * * *", time.Date(2024, 3, 16, 10, 30, 0, 0, lagos)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, lagos)},
+		{"0 12 * jun-aug mon-wed", time.Date(2024, 6, 3, 12, 0, 0, 0, lagos)},
+		{"0 0 13 * 5", time.Date(2024, 3, 22, 0, 0, 0, 0, lagos)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, lagos)},
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, lagos)},
+		{"@hourly", time.Date(2024, 3, 15, 11, 0, 0, 0, lagos)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr, lagos)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(schedule.Next(from)), "got %s", schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCronNeverMatches(t *testing.T) {
+	schedule, err := ParseCron("0 0 31 2 *", nil)
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * funday",
+		"@fortnightly",
+	} {
+		_, err := ParseCron(expr, nil)
+		assert.Error(t, err, expr)
+	}
+}
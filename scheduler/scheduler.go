@@ -0,0 +1,388 @@
+// Package scheduler runs recurring purchases, such as a monthly GOtv
+// renewal or weekly airtime top-up, on cron-like schedules. Jobs are kept
+// in a Store and every run is paid through a VTService under a fresh
+// request ID.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+
+	"github.com/google/uuid"
+)
+
+// ErrVerificationFailed is reported when the smartcard or meter of a job
+// cannot be verified, in which case nothing is paid.
+var ErrVerificationFailed = errors.New("customer verification failed")
+
+// ErrNoRenewalAmount is reported for a TV renewal without an amount or
+// variation code when VTPass returns no renewal amount for the smartcard.
+// Nothing is paid.
+var ErrNoRenewalAmount = errors.New("no renewal amount for smartcard")
+
+// CatchUpPolicy decides what happens to runs missed while the scheduler
+// was down.
+type CatchUpPolicy string
+
+const (
+	// CatchUpOnce makes a single purchase for any number of missed runs.
+	// It suits renewals, where paying twice buys nothing.
+	CatchUpOnce CatchUpPolicy = "once"
+	// CatchUpAll makes one purchase per missed run, up to MaxCatchUp.
+	CatchUpAll CatchUpPolicy = "all"
+	// CatchUpSkip drops missed runs and waits for the next one.
+	CatchUpSkip CatchUpPolicy = "skip"
+)
+
+// Job is a recurring purchase.
+type Job struct {
+	ID string `json:"id"`
+	// Schedule is a cron expression accepted by ParseCron.
+	Schedule string `json:"schedule"`
+	// TimeZone is the IANA zone Schedule is evaluated in. Defaults to
+	// Africa/Lagos.
+	TimeZone string `json:"time_zone,omitempty"`
+	// Purchase is paid on every run. Its RequestID is replaced with a
+	// fresh one each time. For TV renewals with no Amount, the renewal
+	// amount of the verified smartcard is paid as a "renew" subscription.
+	Purchase vt.PurchaseRequest `json:"purchase"`
+	// CatchUp overrides the scheduler's policy for this job.
+	CatchUp CatchUpPolicy `json:"catch_up,omitempty"`
+	Paused  bool          `json:"paused"`
+
+	NextRun    time.Time            `json:"next_run"`
+	LastRun    time.Time            `json:"last_run,omitempty"`
+	LastStatus vt.TransactionStatus `json:"last_status,omitempty"`
+	LastError  string               `json:"last_error,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+// Outcome is the result of one run of a job.
+type Outcome struct {
+	JobID string
+	// ScheduledAt is the run time the purchase was made for.
+	ScheduledAt time.Time
+	// CatchUp is set for runs made after their scheduled time was missed.
+	CatchUp   bool
+	RequestID string
+	// Customer is the verified smartcard or meter owner, if any.
+	Customer *vt.CustomerInfo
+	Status   vt.TransactionStatus
+	Response *vt.PayResponse
+	Err      error
+}
+
+// Options configures a Scheduler.
+type Options struct {
+	// Interval between checks for due jobs in Run. Defaults to one minute.
+	Interval time.Duration
+	// CatchUp is the policy for jobs that do not set one. Defaults to
+	// CatchUpOnce.
+	CatchUp CatchUpPolicy
+	// Grace is how late a run may start and still count as on time rather
+	// than missed. Defaults to five minutes.
+	Grace time.Duration
+	// MaxCatchUp caps the purchases CatchUpAll makes for one job at once.
+	// Defaults to 12.
+	MaxCatchUp int
+	// OnOutcome is called after every run.
+	OnOutcome func(Outcome)
+}
+
+// Scheduler runs due jobs.
+type Scheduler struct {
+	service *vt.VTService
+	store   Store
+	opts    Options
+	mu      sync.Mutex
+}
+
+// New returns a Scheduler paying through service for the jobs in store.
+func New(service *vt.VTService, store Store, opts Options) *Scheduler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.CatchUp == "" {
+		opts.CatchUp = CatchUpOnce
+	}
+	if opts.Grace <= 0 {
+		opts.Grace = 5 * time.Minute
+	}
+	if opts.MaxCatchUp <= 0 {
+		opts.MaxCatchUp = 12
+	}
+	return &Scheduler{service: service, store: store, opts: opts}
+}
+
+// Add validates job, assigns it an ID if it has none, schedules its first
+// run and stores it.
+func (s *Scheduler) Add(ctx context.Context, job Job) (*Job, error) {
+	schedule, err := jobSchedule(job)
+	if err != nil {
+		return nil, err
+	}
+	if job.Purchase.ServiceID == "" || job.Purchase.Phone == "" {
+		return nil, errors.New("scheduler: purchase needs a service ID and phone")
+	}
+	switch job.CatchUp {
+	case "", CatchUpOnce, CatchUpAll, CatchUpSkip:
+	default:
+		return nil, fmt.Errorf("scheduler: unknown catch-up policy %q", job.CatchUp)
+	}
+
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	now := time.Now()
+	job.CreatedAt = now
+	job.NextRun = schedule.Next(now)
+	if job.NextRun.IsZero() {
+		return nil, fmt.Errorf("scheduler: schedule %q never fires", job.Schedule)
+	}
+	if err := s.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Remove deletes the job with id.
+func (s *Scheduler) Remove(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+// Pause stops the job with id from running until it is resumed.
+func (s *Scheduler) Pause(ctx context.Context, id string) error {
+	job, err := s.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	job.Paused = true
+	return s.store.Save(ctx, *job)
+}
+
+// Resume restarts a paused job from its next run time. Runs that fell
+// while it was paused are not caught up.
+func (s *Scheduler) Resume(ctx context.Context, id string) error {
+	job, err := s.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	schedule, err := jobSchedule(*job)
+	if err != nil {
+		return err
+	}
+	job.Paused = false
+	job.NextRun = schedule.Next(time.Now())
+	return s.store.Save(ctx, *job)
+}
+
+// Run checks for due jobs every Interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Tick(ctx, time.Now()); err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs the jobs due at now and returns their outcomes. Each job's
+// next run is stored before it is paid, so this scheduler does not repeat
+// a run after a crash. Schedulers sharing a Store may still both pick up
+// a run; every run is paid under an idempotency key made from the job ID
+// and run time, and when the service has an IdempotencyStore a key that
+// is already recorded is not paid again.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) ([]Outcome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.store.Due(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []Outcome
+	for _, job := range jobs {
+		results, err := s.runJob(ctx, job, now)
+		if err != nil {
+			return outcomes, fmt.Errorf("job %s: %w", job.ID, err)
+		}
+		outcomes = append(outcomes, results...)
+	}
+	return outcomes, nil
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job Job, now time.Time) ([]Outcome, error) {
+	schedule, err := jobSchedule(job)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := s.runsFor(job, schedule, now)
+	job.NextRun = schedule.Next(now)
+	if err := s.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]Outcome, 0, len(runs))
+	for _, run := range runs {
+		outcome := s.execute(ctx, job, run)
+		outcomes = append(outcomes, outcome)
+
+		job.LastRun = now
+		job.LastStatus = outcome.Status
+		job.LastError = ""
+		if outcome.Err != nil {
+			job.LastError = outcome.Err.Error()
+		}
+		if s.opts.OnOutcome != nil {
+			s.opts.OnOutcome(outcome)
+		}
+	}
+
+	if len(runs) > 0 {
+		if err := s.store.Save(ctx, job); err != nil {
+			return outcomes, err
+		}
+	}
+	return outcomes, nil
+}
+
+// scheduledRun is one run time a job is paid for.
+type scheduledRun struct {
+	at      time.Time
+	catchUp bool
+}
+
+// runsFor applies the catch-up policy to the run times of job up to now.
+func (s *Scheduler) runsFor(job Job, schedule Schedule, now time.Time) []scheduledRun {
+	policy := job.CatchUp
+	if policy == "" {
+		policy = s.opts.CatchUp
+	}
+
+	var runs []scheduledRun
+	for at := job.NextRun; !at.IsZero() && !at.After(now); at = schedule.Next(at) {
+		runs = append(runs, scheduledRun{at: at, catchUp: now.Sub(at) > s.opts.Grace})
+		if len(runs) > s.opts.MaxCatchUp {
+			// Keep the most recent runs only.
+			runs = runs[1:]
+		}
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	last := runs[len(runs)-1]
+	switch policy {
+	case CatchUpSkip:
+		if last.catchUp {
+			return nil
+		}
+		return []scheduledRun{last}
+	case CatchUpAll:
+		return runs
+	}
+	return []scheduledRun{last}
+}
+
+// execute verifies the customer where the service has one and pays for a
+// single run. A run already recorded under its idempotency key, e.g. by
+// another scheduler, is reported from the record instead of paid again.
+func (s *Scheduler) execute(ctx context.Context, job Job, run scheduledRun) Outcome {
+	purchase := job.Purchase
+	purchase.RequestID = s.service.GenerateRequestID()
+	purchase.IdempotencyKey = fmt.Sprintf("%s@%s", job.ID, run.at.UTC().Format(time.RFC3339))
+
+	outcome := Outcome{JobID: job.ID, ScheduledAt: run.at, CatchUp: run.catchUp, RequestID: purchase.RequestID}
+
+	record, err := s.service.TransactionByIdempotencyKey(ctx, purchase.IdempotencyKey)
+	switch {
+	case err == nil:
+		outcome.RequestID = record.RequestID
+		outcome.Status = record.Status
+		return outcome
+	case !errors.Is(err, vt.ErrTransactionNotFound) && !errors.Is(err, vt.ErrNoTransactionStore):
+		// Paying without knowing whether the run was paid could pay twice.
+		outcome.Status = vt.StatusFailed
+		outcome.Err = err
+		return outcome
+	}
+
+	customer, err := s.service.VerifyPurchaseCustomer(ctx, purchase)
+	outcome.Customer = customer
+	if err != nil {
+		outcome.Status = vt.StatusFailed
+		outcome.Err = fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+		return outcome
+	}
+	if customer != nil && vt.IsTVService(purchase.ServiceID) && purchase.VariationCode == "" && purchase.Amount == 0 {
+		purchase.Amount = customer.RenewalAmount.Float64()
+		if purchase.Amount <= 0 {
+			outcome.Status = vt.StatusFailed
+			outcome.Err = fmt.Errorf("%w %s", ErrNoRenewalAmount, purchase.BillersCode)
+			return outcome
+		}
+		if purchase.SubscriptionType == "" {
+			purchase.SubscriptionType = "renew"
+		}
+	}
+
+	resp, err := s.service.Purchase(ctx, purchase)
+	if errors.Is(err, vt.ErrDuplicateIdempotencyKey) {
+		// Another scheduler recorded the run first.
+		if record, lookupErr := s.service.TransactionByIdempotencyKey(ctx, purchase.IdempotencyKey); lookupErr == nil {
+			outcome.RequestID = record.RequestID
+			outcome.Status = record.Status
+			return outcome
+		}
+	}
+	var errorResponse vt.ErrorResponse
+	switch {
+	case err == nil:
+	case errors.Is(err, vt.ErrLiveLocked),
+		errors.As(err, &errorResponse) && !vt.IsDuplicateRequestID(err):
+		// Nothing was sent, or VTPass refused the purchase.
+		outcome.Status = vt.StatusFailed
+		outcome.Err = err
+		return outcome
+	default:
+		// HTTP and transport errors may come after the request reached
+		// VTPass, so the run is pending until RequestID is requeried.
+		outcome.Status = vt.StatusPending
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Response = resp
+	outcome.Status = resp.Status()
+	return outcome
+}
+
+func jobSchedule(job Job) (Schedule, error) {
+	zone := job.TimeZone
+	if zone == "" {
+		zone = "Africa/Lagos"
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		if job.TimeZone != "" {
+			return nil, fmt.Errorf("scheduler: time zone %q: %w", job.TimeZone, err)
+		}
+		loc = time.FixedZone("WAT", 60*60)
+	}
+	return ParseCron(job.Schedule, loc)
+}
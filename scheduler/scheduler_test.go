@@ -0,0 +1,322 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+	"github.com/CeoFred/vtpass-go/internal/vtpasstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVTPass answers VTPass calls and records the purchases made.
+type fakeVTPass struct {
+	mu       sync.Mutex
+	paid     []map[string]interface{}
+	verified int
+	verify   string
+	// payStatus and payBody replace the delivered response to pay.
+	payStatus int
+	payBody   string
+}
+
+func (f *fakeVTPass) service(t *testing.T, opts ...vt.Option) *vt.VTService {
+	return vtpasstest.NewService(t, func(endpoint string, body map[string]interface{}) (int, string) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		status, resp := http.StatusOK, `{}`
+		switch endpoint {
+		case "merchant-verify":
+			f.verified++
+			resp = f.verify
+		case "pay":
+			f.paid = append(f.paid, body)
+			resp = `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+			if f.payBody != "" {
+				status, resp = f.payStatus, f.payBody
+			}
+		}
+		return status, resp
+	}, opts...)
+}
+
+func airtimeJob() Job {
+	return Job{
+		Schedule: "0 8 * * FRI",
+		Purchase: vt.PurchaseRequest{ServiceID: "mtn", Amount: 1000, Phone: "08011111111"},
+	}
+}
+
+func TestAddValidates(t *testing.T) {
+	s := New((&fakeVTPass{}).service(t), NewMemoryStore(), Options{})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, airtimeJob())
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.True(t, job.NextRun.After(time.Now()))
+	assert.Equal(t, time.Friday, job.NextRun.Weekday())
+
+	bad := airtimeJob()
+	bad.Schedule = "every friday"
+	_, err = s.Add(ctx, bad)
+	assert.Error(t, err)
+
+	bad = airtimeJob()
+	bad.Purchase.Phone = ""
+	_, err = s.Add(ctx, bad)
+	assert.Error(t, err)
+
+	bad = airtimeJob()
+	bad.CatchUp = "sometimes"
+	_, err = s.Add(ctx, bad)
+	assert.Error(t, err)
+
+	bad = airtimeJob()
+	bad.TimeZone = "Mars/Olympus"
+	_, err = s.Add(ctx, bad)
+	assert.Error(t, err)
+
+	// February never has a 30th.
+	bad = airtimeJob()
+	bad.Schedule = "0 8 30 2 *"
+	_, err = s.Add(ctx, bad)
+	assert.ErrorContains(t, err, "never fires")
+}
+
+func TestTickRunsDueJobsWithFreshRequestIDs(t *testing.T) {
+	fake := &fakeVTPass{}
+	store := NewMemoryStore()
+	var reported []Outcome
+	s := New(fake.service(t), store, Options{OnOutcome: func(o Outcome) { reported = append(reported, o) }})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, airtimeJob())
+	require.NoError(t, err)
+	first := job.NextRun
+
+	outcomes, err := s.Tick(ctx, first.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, outcomes)
+
+	outcomes, err = s.Tick(ctx, first)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.Equal(t, vt.StatusDelivered, outcomes[0].Status)
+	assert.False(t, outcomes[0].CatchUp)
+
+	second := first.AddDate(0, 0, 7)
+	outcomes, err = s.Tick(ctx, second)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+
+	require.Len(t, fake.paid, 2)
+	assert.NotEqual(t, fake.paid[0]["request_id"], fake.paid[1]["request_id"])
+	assert.Len(t, reported, 2)
+
+	stored, err := store.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, vt.StatusDelivered, stored.LastStatus)
+	assert.True(t, stored.NextRun.Equal(second.AddDate(0, 0, 7)))
+}
+
+func TestCatchUpPolicies(t *testing.T) {
+	tests := []struct {
+		policy CatchUpPolicy
+		want   int
+	}{
+		{CatchUpOnce, 1},
+		{CatchUpAll, 3},
+		{CatchUpSkip, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			fake := &fakeVTPass{}
+			s := New(fake.service(t), NewMemoryStore(), Options{CatchUp: tt.policy})
+			ctx := context.Background()
+
+			job, err := s.Add(ctx, airtimeJob())
+			require.NoError(t, err)
+
+			// Down for three Fridays, back on Saturday.
+			now := job.NextRun.AddDate(0, 0, 15)
+			outcomes, err := s.Tick(ctx, now)
+			require.NoError(t, err)
+			assert.Len(t, outcomes, tt.want)
+			assert.Len(t, fake.paid, tt.want)
+			for _, o := range outcomes {
+				assert.True(t, o.CatchUp)
+			}
+
+			// Missed runs are not repeated on the next tick.
+			outcomes, err = s.Tick(ctx, now.Add(time.Minute))
+			require.NoError(t, err)
+			assert.Empty(t, outcomes)
+		})
+	}
+}
+
+func TestCatchUpAllIsCapped(t *testing.T) {
+	fake := &fakeVTPass{}
+	s := New(fake.service(t), NewMemoryStore(), Options{MaxCatchUp: 2})
+	ctx := context.Background()
+
+	job := airtimeJob()
+	job.CatchUp = CatchUpAll
+	added, err := s.Add(ctx, job)
+	require.NoError(t, err)
+
+	outcomes, err := s.Tick(ctx, added.NextRun.AddDate(0, 0, 28))
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2)
+	assert.True(t, outcomes[1].ScheduledAt.Equal(added.NextRun.AddDate(0, 0, 28)))
+}
+
+func TestSmartcardVerifiedBeforeRenewal(t *testing.T) {
+	fake := &fakeVTPass{verify: `{"code":"000","content":{"Customer_Name":"ADA OBI","Renewal_Amount":"4850.00"}}`}
+	s := New(fake.service(t), NewMemoryStore(), Options{})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, Job{
+		Schedule: "0 9 1 * *",
+		Purchase: vt.PurchaseRequest{ServiceID: "gotv", BillersCode: "1212121212", Phone: "08011111111"},
+	})
+	require.NoError(t, err)
+
+	outcomes, err := s.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.NoError(t, outcomes[0].Err)
+	assert.Equal(t, "ADA OBI", outcomes[0].Customer.CustomerName)
+	assert.Equal(t, 1, fake.verified)
+	require.Len(t, fake.paid, 1)
+	assert.Equal(t, 4850.0, fake.paid[0]["amount"])
+	assert.Equal(t, "renew", fake.paid[0]["subscription_type"])
+}
+
+func TestRenewalWithoutAmountIsNotPaid(t *testing.T) {
+	fake := &fakeVTPass{verify: `{"code":"000","content":{"Customer_Name":"ADA OBI","Renewal_Amount":"0"}}`}
+	s := New(fake.service(t), NewMemoryStore(), Options{})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, Job{
+		Schedule: "0 9 1 * *",
+		Purchase: vt.PurchaseRequest{ServiceID: "gotv", BillersCode: "1212121212", Phone: "08011111111"},
+	})
+	require.NoError(t, err)
+
+	outcomes, err := s.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.ErrorIs(t, outcomes[0].Err, ErrNoRenewalAmount)
+	assert.Equal(t, vt.StatusFailed, outcomes[0].Status)
+	assert.Empty(t, fake.paid)
+}
+
+func TestPurchaseErrorOutcomes(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus vt.TransactionStatus
+	}{
+		{"refused", http.StatusOK, `{"code":"018","response_description":"LOW WALLET BALANCE"}`, vt.StatusFailed},
+		{"gateway timeout", http.StatusGatewayTimeout, `<html>Gateway Timeout</html>`, vt.StatusPending},
+		{"reused request ID", http.StatusOK, `{"code":"014"}`, vt.StatusPending},
+	} {
+		fake := &fakeVTPass{payStatus: tc.status, payBody: tc.body}
+		s := New(fake.service(t), NewMemoryStore(), Options{})
+		ctx := context.Background()
+
+		job, err := s.Add(ctx, airtimeJob())
+		require.NoError(t, err)
+
+		outcomes, err := s.Tick(ctx, job.NextRun)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 1, tc.name)
+		assert.Error(t, outcomes[0].Err, tc.name)
+		assert.Equal(t, tc.wantStatus, outcomes[0].Status, tc.name)
+		assert.NotEmpty(t, outcomes[0].RequestID, tc.name)
+	}
+}
+
+func TestInvalidSmartcardIsNotPaid(t *testing.T) {
+	fake := &fakeVTPass{verify: `{"code":"000","content":{"WrongBillersCode":true,"error":"invalid smartcard"}}`}
+	store := NewMemoryStore()
+	s := New(fake.service(t), store, Options{})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, Job{
+		Schedule: "0 9 1 * *",
+		Purchase: vt.PurchaseRequest{ServiceID: "dstv", BillersCode: "0000000000", Amount: 4615, Phone: "08011111111"},
+	})
+	require.NoError(t, err)
+
+	outcomes, err := s.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.ErrorIs(t, outcomes[0].Err, ErrVerificationFailed)
+	assert.Equal(t, vt.StatusFailed, outcomes[0].Status)
+	assert.Empty(t, fake.paid)
+
+	stored, err := store.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Contains(t, stored.LastError, "invalid smartcard")
+}
+
+func TestRunIsPaidOnceAcrossSchedulers(t *testing.T) {
+	fake := &fakeVTPass{}
+	service := fake.service(t, vt.WithTransactionStore(vt.NewMemoryTransactionStore()))
+	ctx := context.Background()
+
+	first := New(service, NewMemoryStore(), Options{})
+	job, err := first.Add(ctx, airtimeJob())
+	require.NoError(t, err)
+
+	// A second scheduler that read the job before the first one stored
+	// its next run.
+	stale := NewMemoryStore()
+	require.NoError(t, stale.Save(ctx, *job))
+	second := New(service, stale, Options{})
+
+	paid, err := first.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	require.Len(t, paid, 1)
+
+	replayed, err := second.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.NoError(t, replayed[0].Err)
+	assert.Equal(t, paid[0].RequestID, replayed[0].RequestID)
+	assert.Equal(t, vt.StatusDelivered, replayed[0].Status)
+	assert.Len(t, fake.paid, 1)
+}
+
+func TestPauseAndResume(t *testing.T) {
+	fake := &fakeVTPass{}
+	store := NewMemoryStore()
+	s := New(fake.service(t), store, Options{})
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, airtimeJob())
+	require.NoError(t, err)
+	require.NoError(t, s.Pause(ctx, job.ID))
+
+	outcomes, err := s.Tick(ctx, job.NextRun)
+	require.NoError(t, err)
+	assert.Empty(t, outcomes)
+
+	require.NoError(t, s.Resume(ctx, job.ID))
+	resumed, err := store.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.False(t, resumed.Paused)
+	assert.True(t, resumed.NextRun.After(time.Now()))
+
+	require.NoError(t, s.Remove(ctx, job.ID))
+	assert.ErrorIs(t, s.Pause(ctx, job.ID), ErrJobNotFound)
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is returned for unknown job IDs.
+var ErrJobNotFound = errors.New("job not found")
+
+// Store persists jobs. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save inserts the job or replaces the one with the same ID.
+	Save(ctx context.Context, job Job) error
+	// Get returns ErrJobNotFound for unknown IDs.
+	Get(ctx context.Context, id string) (*Job, error)
+	// Delete returns ErrJobNotFound for unknown IDs.
+	Delete(ctx context.Context, id string) error
+	// List returns every job, ordered by ID.
+	List(ctx context.Context) ([]Job, error)
+	// Due returns the jobs that are not paused and whose NextRun is at or
+	// before now.
+	Due(ctx context.Context, now time.Time) ([]Job, error)
+}
+
+// MemoryStore is an in-memory Store. Jobs are lost on restart.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, job Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.jobs[id]; !ok {
+		return ErrJobNotFound
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+func (m *MemoryStore) List(ctx context.Context) ([]Job, error) {
+	return m.filter(func(Job) bool { return true }), nil
+}
+
+func (m *MemoryStore) Due(ctx context.Context, now time.Time) ([]Job, error) {
+	return m.filter(func(job Job) bool {
+		return !job.Paused && !job.NextRun.IsZero() && !job.NextRun.After(now)
+	}), nil
+}
+
+func (m *MemoryStore) filter(keep func(Job) bool) []Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if keep(job) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// ErrorBody is the JSON body of every error response. Code is the VTPass
+// response code when the error came from VTPass.
+type ErrorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// errorBody maps err to an HTTP status and body.
+func errorBody(err error) (int, ErrorBody) {
+	var errorResponse vt.ErrorResponse
+	switch {
+	case errors.As(err, &errorResponse):
+		status := http.StatusBadGateway
+		switch errorResponse.Code {
+		case vt.INVALID_ARGUMENTS, vt.PRODUCT_DOES_NOT_EXIST, vt.BELOW_MINIMUM_AMOUNT, vt.ABOVE_MAXIMUM_AMOUNT:
+			status = http.StatusBadRequest
+		case vt.REQUEST_ID_ALREADY_EXISTS, vt.LIKELY_DUPLICATE_TRANSACTION:
+			status = http.StatusConflict
+		case vt.LOW_WALLET_BALANCE:
+			status = http.StatusPaymentRequired
+		case vt.TRANSACTION_FAILED:
+			status = http.StatusUnprocessableEntity
+		}
+		return status, ErrorBody{Error: errorResponse.Error(), Code: errorResponse.Code}
+	case errors.Is(err, vt.ErrUnknownDisco), errors.Is(err, vt.ErrInvalidMeterType), errors.Is(err, vt.ErrInvalidMeterNumber):
+		return http.StatusBadRequest, ErrorBody{Error: err.Error()}
+	case errors.Is(err, vt.ErrTransactionNotFound):
+		return http.StatusNotFound, ErrorBody{Error: err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrorBody{Error: err.Error()}
+	}
+	return http.StatusBadGateway, ErrorBody{Error: err.Error()}
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	status, body := errorBody(err)
+	writeJSON(w, status, body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, ErrorBody{Error: message, Code: code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	writeRaw(w, status, marshal(v))
+}
+
+func writeRaw(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func marshal(v interface{}) []byte {
+	body, err := json.Marshal(v)
+	if err != nil {
+		body, _ = json.Marshal(ErrorBody{Error: err.Error()})
+	}
+	return append(body, '\n')
+}
@@ -0,0 +1,477 @@
+// Package server exposes a VTService over REST so that services written in
+// other languages can use VTPass without holding its credentials.
+//
+// Routes:
+//
+//	GET  /balance
+//	GET  /catalog/categories
+//	GET  /catalog/services/{identifier}
+//	GET  /catalog/variations/{serviceID}
+//	POST /verify/{serviceID}
+//	POST /purchase/{serviceID}
+//	GET  /transactions/{requestID}
+//	POST /webhooks/vtpass
+//
+// Every route except the webhook and /healthz requires an API key in the
+// X-API-Key header or as a bearer token. The webhook is only served when a
+// WebhookToken is configured.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// IdempotencyKeyHeader carries the caller's idempotency key on purchases.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Config configures a Server.
+type Config struct {
+	Service *vt.VTService
+	// APIKeys are the keys accepted from internal callers. At least one is
+	// required.
+	APIKeys []string
+	// WebhookPath is where VTPass posts transaction updates. Defaults to
+	// /webhooks/vtpass.
+	WebhookPath string
+	// WebhookToken must be passed as the token query parameter of the
+	// webhook URL registered with VTPass. The webhook route is not served
+	// without one.
+	WebhookToken string
+	// OnTransactionUpdate is called with the saved record after a webhook
+	// has been confirmed and applied to the transaction store. It is not
+	// called for updates that were rejected or could not be confirmed.
+	OnTransactionUpdate func(ctx context.Context, record *vt.TransactionRecord)
+	// IdempotencyTTL is how long purchase responses are replayed for a
+	// repeated idempotency key without a store lookup. Defaults to 24 hours.
+	IdempotencyTTL time.Duration
+}
+
+// Server is an http.Handler serving the gateway routes.
+type Server struct {
+	cfg     Config
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a purchase made with an idempotency key. done is closed once
+// the response is known. The request ID stays bound to the key for the
+// flight's lifetime, so that a retry after an unknown outcome settles the
+// same purchase instead of paying for a new one.
+type flight struct {
+	done      chan struct{}
+	requestID string
+	status    int
+	body      []byte
+	expires   time.Time
+	// unknown is set when the purchase may or may not have reached VTPass.
+	unknown bool
+}
+
+// New returns a Server for cfg.
+func New(cfg Config) (*Server, error) {
+	if cfg.Service == nil {
+		return nil, errors.New("server: Service is required")
+	}
+	if len(cfg.APIKeys) == 0 {
+		return nil, errors.New("server: at least one API key is required")
+	}
+	if cfg.WebhookPath == "" {
+		cfg.WebhookPath = "/webhooks/vtpass"
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = 24 * time.Hour
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), flights: make(map[string]*flight)}
+	s.mux.Handle("/balance", s.authenticate(s.method(http.MethodGet, s.handleBalance)))
+	s.mux.Handle("/catalog/", s.authenticate(s.method(http.MethodGet, s.handleCatalog)))
+	s.mux.Handle("/verify/", s.authenticate(s.method(http.MethodPost, s.handleVerify)))
+	s.mux.Handle("/purchase/", s.authenticate(s.method(http.MethodPost, s.handlePurchase)))
+	s.mux.Handle("/transactions/", s.authenticate(s.method(http.MethodGet, s.handleTransaction)))
+	if cfg.WebhookToken != "" {
+		s.mux.Handle(cfg.WebhookPath, s.method(http.MethodPost, s.handleWebhook))
+	}
+	s.mux.Handle("/healthz", s.method(http.MethodGet, s.handleHealth))
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			key = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		for _, allowed := range s.cfg.APIKeys {
+			if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		writeError(w, http.StatusUnauthorized, "", "invalid API key")
+	})
+}
+
+func (s *Server) method(method string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, "", "method not allowed")
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := s.cfg.Service.Balance(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	amount, err := vt.ParseMoney(balance.Contents.Balance)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"balance": amount.Naira()})
+}
+
+// handleHealth serves readiness probes and is not authenticated. It
+// answers 503 when VTPass is unreachable or rejects the credentials.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := s.cfg.Service.Health(r.Context())
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func (s *Server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/catalog/"), "/"), "/")
+	ctx := r.Context()
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch {
+	case len(parts) == 1 && parts[0] == "categories":
+		result, err = s.cfg.Service.ServiceCategories(ctx)
+	case len(parts) == 2 && parts[0] == "services":
+		result, err = s.cfg.Service.ServiceByIdentifier(ctx, parts[1])
+	case len(parts) == 2 && parts[0] == "variations":
+		result, err = s.cfg.Service.ServiceVariations(ctx, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "", "not found")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// VerifyRequest is the body of POST /verify/{serviceID}.
+type VerifyRequest struct {
+	BillersCode string `json:"billers_code"`
+	// Type is the meter type for electricity billers.
+	Type string `json:"type,omitempty"`
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	serviceID, ok := pathParam(r, "/verify/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "not found")
+		return
+	}
+
+	var req VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BillersCode == "" {
+		writeError(w, http.StatusBadRequest, "", "billers_code is required")
+		return
+	}
+
+	var (
+		customer *vt.CustomerInfo
+		err      error
+	)
+	if disco, parseErr := vt.ParseDisco(serviceID); parseErr == nil {
+		customer, err = s.cfg.Service.VerifyMeterNumber(r.Context(), req.BillersCode, vt.MeterType(req.Type), disco)
+	} else {
+		customer, err = s.cfg.Service.VerifyCustomer(r.Context(), serviceID, req.BillersCode, req.Type)
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, customer)
+}
+
+// PurchaseRequest is the body of POST /purchase/{serviceID}.
+type PurchaseRequest struct {
+	RequestID        string  `json:"request_id,omitempty"`
+	BillersCode      string  `json:"billers_code,omitempty"`
+	VariationCode    string  `json:"variation_code,omitempty"`
+	Amount           float64 `json:"amount,omitempty"`
+	Phone            string  `json:"phone"`
+	SubscriptionType string  `json:"subscription_type,omitempty"`
+	Quantity         int     `json:"quantity,omitempty"`
+}
+
+// TransactionResult is returned by the purchase and transaction routes.
+type TransactionResult struct {
+	RequestID   string               `json:"request_id"`
+	Status      vt.TransactionStatus `json:"status"`
+	Transaction vt.Transaction       `json:"transaction"`
+	// Response is VTPass's response, as received.
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+func (s *Server) handlePurchase(w http.ResponseWriter, r *http.Request) {
+	serviceID, ok := pathParam(r, "/purchase/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "not found")
+		return
+	}
+
+	var req PurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "", "invalid request body")
+		return
+	}
+	if req.Phone == "" {
+		writeError(w, http.StatusBadRequest, "", "phone is required")
+		return
+	}
+
+	purchase := vt.PurchaseRequest{
+		RequestID:        req.RequestID,
+		ServiceID:        serviceID,
+		BillersCode:      req.BillersCode,
+		VariationCode:    req.VariationCode,
+		Amount:           req.Amount,
+		Phone:            req.Phone,
+		SubscriptionType: req.SubscriptionType,
+		Quantity:         req.Quantity,
+		IdempotencyKey:   r.Header.Get(IdempotencyKeyHeader),
+	}
+	if purchase.IdempotencyKey == "" {
+		status, body, _ := s.purchase(r.Context(), purchase)
+		writeRaw(w, status, body)
+		return
+	}
+
+	f, leader := s.claim(purchase.IdempotencyKey, purchase.RequestID)
+	if !leader {
+		select {
+		case <-f.done:
+			w.Header().Set("Idempotent-Replayed", "true")
+			writeRaw(w, f.status, f.body)
+		case <-r.Context().Done():
+		}
+		return
+	}
+
+	purchase.RequestID = f.requestID
+	if record, err := s.cfg.Service.TransactionByIdempotencyKey(r.Context(), purchase.IdempotencyKey); err == nil {
+		// Paid or attempted before, perhaps by another gateway instance.
+		purchase.RequestID = record.RequestID
+		if record.Status == vt.StatusInitiated {
+			f.status, f.body, f.unknown = s.settle(r.Context(), purchase)
+		} else {
+			if refreshed, err := s.cfg.Service.Transaction(r.Context(), record.RequestID); err == nil {
+				record = refreshed
+			}
+			if status, body, ok := refusal(record); ok {
+				f.status, f.body = status, body
+			} else {
+				f.status, f.body = http.StatusOK, marshal(resultFromRecord(record))
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+	} else if f.unknown {
+		f.status, f.body, f.unknown = s.settle(r.Context(), purchase)
+	} else {
+		f.status, f.body, f.unknown = s.purchase(r.Context(), purchase)
+	}
+	f.requestID = purchase.RequestID
+	s.finish(purchase.IdempotencyKey, f)
+	writeRaw(w, f.status, f.body)
+}
+
+// claim returns the flight for key and whether the caller must make the
+// purchase. Expired flights are dropped. A finished flight whose outcome
+// is unknown is handed to the caller to settle under the same request ID;
+// requestID is used for new flights and generated when empty.
+func (s *Server) claim(key, requestID string) (*flight, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, f := range s.flights {
+		if !f.expires.IsZero() && now.After(f.expires) {
+			delete(s.flights, k)
+		}
+	}
+
+	if f, ok := s.flights[key]; ok {
+		select {
+		case <-f.done:
+			if !f.unknown {
+				return f, false
+			}
+			retry := &flight{done: make(chan struct{}), requestID: f.requestID, unknown: true}
+			s.flights[key] = retry
+			return retry, true
+		default:
+			return f, false
+		}
+	}
+
+	if requestID == "" {
+		requestID = s.cfg.Service.GenerateRequestID()
+	}
+	f := &flight{done: make(chan struct{}), requestID: requestID}
+	s.flights[key] = f
+	return f, true
+}
+
+// finish publishes a flight's response and keeps it for IdempotencyTTL.
+// Purchases VTPass refused stay bound to their key too: a retry with the
+// same key gets the original error, here and from the transaction store
+// after a restart, and must use a new key to try again.
+func (s *Server) finish(key string, f *flight) {
+	s.mu.Lock()
+	f.expires = time.Now().Add(s.cfg.IdempotencyTTL)
+	s.mu.Unlock()
+	close(f.done)
+}
+
+// refusal rebuilds the error response for a stored purchase that VTPass
+// refused. Such records are failed, carry VTPass's error body and no
+// transaction.
+func refusal(record *vt.TransactionRecord) (int, []byte, bool) {
+	if record.Status != vt.StatusFailed || record.Transaction.Status != "" {
+		return 0, nil, false
+	}
+	var base vt.BaseResponse
+	if err := json.Unmarshal(record.Response, &base); err != nil || base.Code == "" {
+		return 0, nil, false
+	}
+	status, body := errorBody(vt.ErrorResponse{BaseResponse: base})
+	return status, marshal(body), true
+}
+
+// purchase pays for req. unknown reports whether the purchase may have
+// reached VTPass despite the error.
+func (s *Server) purchase(ctx context.Context, req vt.PurchaseRequest) (status int, body []byte, unknown bool) {
+	if req.RequestID == "" {
+		req.RequestID = s.cfg.Service.GenerateRequestID()
+	}
+
+	resp, err := s.cfg.Service.Purchase(ctx, req)
+	if err != nil {
+		status, errBody := errorBody(err)
+		var errorResponse vt.ErrorResponse
+		return status, marshal(errBody), !errors.As(err, &errorResponse) || vt.IsDuplicateRequestID(err)
+	}
+
+	return http.StatusOK, marshal(TransactionResult{
+		RequestID:   req.RequestID,
+		Status:      resp.Status(),
+		Transaction: resp.Content.Transactions,
+		Response:    resp.Raw,
+	}), false
+}
+
+// settle resolves a purchase whose outcome is unknown. It is requeried,
+// and paid under the same request ID only if VTPass has no record of it.
+func (s *Server) settle(ctx context.Context, req vt.PurchaseRequest) (status int, body []byte, unknown bool) {
+	txn, err := s.cfg.Service.QueryTransaction(ctx, req.RequestID)
+	if err != nil {
+		status, errBody := errorBody(err)
+		return status, marshal(errBody), true
+	}
+	if txn.Code == vt.INVALID_REQUEST_ID {
+		return s.purchase(ctx, req)
+	}
+
+	result := TransactionResult{
+		RequestID:   req.RequestID,
+		Status:      txn.Status(),
+		Transaction: txn.Content.Transactions,
+		Response:    txn.Raw,
+	}
+	if result.Status == "" {
+		result.Status = vt.StatusPending
+	}
+	return http.StatusOK, marshal(result), false
+}
+
+func (s *Server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	requestID, ok := pathParam(r, "/transactions/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "not found")
+		return
+	}
+
+	record, err := s.cfg.Service.Transaction(r.Context(), requestID)
+	if err == nil {
+		writeJSON(w, http.StatusOK, resultFromRecord(record))
+		return
+	}
+	if !errors.Is(err, vt.ErrNoTransactionStore) {
+		writeServiceError(w, err)
+		return
+	}
+
+	txn, err := s.cfg.Service.QueryTransaction(r.Context(), requestID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if txn.Code == vt.INVALID_REQUEST_ID {
+		writeServiceError(w, vt.ErrTransactionNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, TransactionResult{
+		RequestID:   requestID,
+		Status:      txn.Status(),
+		Transaction: txn.Content.Transactions,
+		Response:    txn.Raw,
+	})
+}
+
+func resultFromRecord(record *vt.TransactionRecord) TransactionResult {
+	return TransactionResult{
+		RequestID:   record.RequestID,
+		Status:      record.Status,
+		Transaction: record.Transaction,
+		Response:    record.Response,
+	}
+}
+
+// pathParam returns the single path segment after prefix.
+func pathParam(r *http.Request, prefix string) (string, bool) {
+	param := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if param == "" || strings.Contains(param, "/") {
+		return "", false
+	}
+	return param, true
+}
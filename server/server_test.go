@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	vt "github.com/CeoFred/vtpass-go"
+	"github.com/CeoFred/vtpass-go/internal/vtpasstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a gateway whose VTPass calls are answered by
+// handle, keyed by endpoint.
+func newTestServer(t *testing.T, store vt.TransactionStore, handle vtpasstest.Handler) *Server {
+	var opts []vt.Option
+	if store != nil {
+		opts = append(opts, vt.WithTransactionStore(store))
+	}
+	srv, err := New(Config{
+		Service: vtpasstest.NewService(t, handle, opts...),
+		APIKeys: []string{"internal-key"},
+	})
+	require.NoError(t, err)
+	return srv
+}
+
+func call(srv http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-API-Key", "internal-key")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthAndCatalog(t *testing.T) {
+	srv := newTestServer(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		switch endpoint {
+		case "balance":
+			return http.StatusOK, `{"code":"000","contents":{"balance":"1,500.25"}}`
+		case "service-variations?serviceID=mtn-data":
+			return http.StatusOK, `{"code":"000","content":{"ServiceName":"MTN Data","varations":[{"variation_code":"mtn-10mb-100","variation_amount":"100.00"}]}}`
+		}
+		return http.StatusNotFound, `{"code":"011"}`
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/balance", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req.Header.Set("Authorization", "Bearer internal-key")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"balance":1500.25}`, rec.Body.String())
+
+	rec = call(srv, http.MethodPost, "/balance", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = call(srv, http.MethodGet, "/catalog/variations/mtn-data", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var variations []vt.Variation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &variations))
+	require.Len(t, variations, 1)
+	assert.Equal(t, "mtn-10mb-100", variations[0].VariationCode)
+
+	rec = call(srv, http.MethodGet, "/catalog/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestVerify(t *testing.T) {
+	srv := newTestServer(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		assert.Equal(t, "merchant-verify", endpoint)
+		assert.Equal(t, "prepaid", body["type"])
+		return http.StatusOK, `{"code":"000","content":{"Customer_Name":"ADA OBI"}}`
+	})
+
+	rec := call(srv, http.MethodPost, "/verify/ikeja-electric", `{"billers_code":"1111111111111","type":"prepaid"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "ADA OBI")
+
+	rec = call(srv, http.MethodPost, "/verify/ikeja-electric", `{"billers_code":"12","type":"prepaid"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPurchaseIdempotency(t *testing.T) {
+	var pays int32
+	store := vt.NewMemoryTransactionStore()
+	srv := newTestServer(t, store, func(endpoint string, body map[string]interface{}) (int, string) {
+		switch endpoint {
+		case "pay":
+			atomic.AddInt32(&pays, 1)
+			if body["phone"] == "08000000000" {
+				return http.StatusOK, `{"code":"018","response_description":"LOW WALLET BALANCE"}`
+			}
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered","transactionId":"17000"}}}`
+		case "requery":
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+		}
+		return http.StatusNotFound, `{}`
+	})
+
+	body := `{"amount":100,"phone":"08011111111"}`
+	headers := map[string]string{IdempotencyKeyHeader: "order-1"}
+
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 5)
+	for i := 0; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = call(srv, http.MethodPost, "/purchase/mtn", body, headers)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+	var first TransactionResult
+	require.NoError(t, json.Unmarshal(results[0].Body.Bytes(), &first))
+	assert.Equal(t, vt.StatusDelivered, first.Status)
+	for _, rec := range results {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, results[0].Body.String(), rec.Body.String())
+	}
+
+	// A restarted gateway replays from the transaction store.
+	restarted, err := New(Config{Service: srv.cfg.Service, APIKeys: []string{"internal-key"}})
+	require.NoError(t, err)
+	rec := call(restarted, http.MethodPost, "/purchase/mtn", body, headers)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+
+	rec = call(srv, http.MethodGet, "/transactions/"+first.RequestID, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"transactionId":"17000"`)
+
+	rec = call(srv, http.MethodPost, "/purchase/mtn", `{"amount":100,"phone":"08000000000"}`, nil)
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	assert.JSONEq(t, `{"error":"LOW WALLET BALANCE","code":"018"}`, rec.Body.String())
+
+	// A refused purchase replays its error under the same key.
+	refused := map[string]string{IdempotencyKeyHeader: "order-3"}
+	for _, gateway := range []*Server{srv, srv, restarted} {
+		rec = call(gateway, http.MethodPost, "/purchase/mtn", `{"amount":100,"phone":"08000000000"}`, refused)
+		assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+		assert.JSONEq(t, `{"error":"LOW WALLET BALANCE","code":"018"}`, rec.Body.String())
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&pays))
+
+	rec = call(srv, http.MethodGet, "/transactions/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebhook(t *testing.T) {
+	store := vt.NewMemoryTransactionStore()
+	require.NoError(t, store.Save(context.Background(), vt.TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: vt.StatusPending}))
+	require.NoError(t, store.Save(context.Background(), vt.TransactionRecord{RequestID: "r2", ServiceID: "mtn", Status: vt.StatusPending}))
+
+	unauthenticated := newTestServer(t, store, func(endpoint string, body map[string]interface{}) (int, string) {
+		if body["request_id"] == "r1" {
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+		}
+		return http.StatusOK, `{"code":"099","content":{"transactions":{"status":"pending"}}}`
+	})
+	update := func(requestID string) string {
+		return `{"type":"transaction-update","data":{"code":"000","requestId":"` + requestID + `","content":{"transactions":{"status":"delivered"}}}}`
+	}
+
+	// Without a token the webhook is not served.
+	rec := httptest.NewRecorder()
+	unauthenticated.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/vtpass", strings.NewReader(update("r1"))))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	var received []string
+	srv, err := New(Config{
+		Service:      unauthenticated.cfg.Service,
+		APIKeys:      []string{"internal-key"},
+		WebhookToken: "hook-secret",
+		OnTransactionUpdate: func(ctx context.Context, record *vt.TransactionRecord) {
+			received = append(received, record.RequestID)
+		},
+	})
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/vtpass?token=wrong", strings.NewReader(update("r1"))))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, id := range []string{"r1", "r2"} {
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/vtpass?token=hook-secret", strings.NewReader(update(id))))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"response":"success"}`, rec.Body.String())
+	}
+	assert.Equal(t, []string{"r1"}, received)
+
+	record, err := store.Get(context.Background(), "r1")
+	require.NoError(t, err)
+	assert.Equal(t, vt.StatusDelivered, record.Status)
+
+	// r2's update claims delivery but VTPass still reports it pending.
+	record, err = store.Get(context.Background(), "r2")
+	require.NoError(t, err)
+	assert.Equal(t, vt.StatusPending, record.Status)
+}
+
+func TestPurchaseRetryAfterUnknownOutcome(t *testing.T) {
+	var pays int32
+	var requestIDs []interface{}
+	srv := newTestServer(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		switch endpoint {
+		case "pay":
+			requestIDs = append(requestIDs, body["request_id"])
+			// The pay reaches VTPass but the gateway in front times out.
+			atomic.AddInt32(&pays, 1)
+			return http.StatusGatewayTimeout, `<html>Gateway Timeout</html>`
+		case "requery":
+			return http.StatusOK, `{"code":"000","content":{"transactions":{"status":"delivered"}}}`
+		}
+		return http.StatusNotFound, `{}`
+	})
+
+	body := `{"amount":100,"phone":"08011111111"}`
+	headers := map[string]string{IdempotencyKeyHeader: "order-2"}
+
+	rec := call(srv, http.MethodPost, "/purchase/mtn", body, headers)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	rec = call(srv, http.MethodPost, "/purchase/mtn", body, headers)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result TransactionResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, vt.StatusDelivered, result.Status)
+	assert.Equal(t, requestIDs[0], result.RequestID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+
+	rec = call(srv, http.MethodPost, "/purchase/mtn", body, headers)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pays))
+}
+
+func TestHealthz(t *testing.T) {
+	var code atomic.Value
+	code.Store("000")
+	srv := newTestServer(t, nil, func(endpoint string, body map[string]interface{}) (int, string) {
+		return http.StatusOK, `{"code":"` + code.Load().(string) + `","contents":{"balance":"10.00"}}`
+	})
+
+	rec := call(srv, http.MethodGet, "/healthz", "", map[string]string{"X-API-Key": ""})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"ok"`)
+	assert.NotContains(t, rec.Body.String(), "10.00")
+
+	code.Store("087")
+	rec = call(srv, http.MethodGet, "/healthz", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"auth_failure"`)
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// handleWebhook receives VTPass transaction updates. Each update is
+// confirmed with a requery before it is saved, and only confirmed updates
+// reach OnTransactionUpdate. VTPass retries until it gets
+// {"response":"success"}, so updates that cannot be applied, such as
+// stale, unconfirmed or unknown ones, are still acknowledged.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if token := s.cfg.WebhookToken; token == "" ||
+		subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "", "invalid webhook token")
+		return
+	}
+
+	var update vt.TransactionUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "", "invalid transaction update")
+		return
+	}
+
+	ctx := r.Context()
+	record, err := s.cfg.Service.ApplyTransactionUpdate(ctx, update)
+	switch {
+	case err == nil:
+		if s.cfg.OnTransactionUpdate != nil {
+			s.cfg.OnTransactionUpdate(ctx, record)
+		}
+	case !errors.Is(err, vt.ErrNoTransactionStore) && !errors.Is(err, vt.ErrInvalidTransition) &&
+		!errors.Is(err, vt.ErrUpdateNotConfirmed):
+		log.Printf("applying transaction update %s failed: %v", update.Data.RequestID, err)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"response": "success"})
+}
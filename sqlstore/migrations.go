@@ -0,0 +1,134 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migration is a schema change. Statements may use {{id}} for the
+// dialect's auto-incrementing primary key column definition.
+type migration struct {
+	version    int
+	statements []string
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE vtpass_transactions (
+				request_id      TEXT PRIMARY KEY,
+				idempotency_key TEXT,
+				service_id      TEXT NOT NULL,
+				billers_code    TEXT NOT NULL DEFAULT '',
+				variation_code  TEXT NOT NULL DEFAULT '',
+				phone           TEXT NOT NULL DEFAULT '',
+				amount          DOUBLE PRECISION NOT NULL DEFAULT 0,
+				status          TEXT NOT NULL,
+				payload         TEXT,
+				response        TEXT,
+				txn             TEXT,
+				created_at      TEXT NOT NULL,
+				updated_at      TEXT NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX vtpass_transactions_idempotency_key
+				ON vtpass_transactions (idempotency_key)
+				WHERE idempotency_key IS NOT NULL`,
+			`CREATE INDEX vtpass_transactions_created_at
+				ON vtpass_transactions (created_at, request_id)`,
+			`CREATE TABLE vtpass_transaction_events (
+				id          {{id}},
+				request_id  TEXT NOT NULL REFERENCES vtpass_transactions (request_id),
+				from_status TEXT NOT NULL DEFAULT '',
+				to_status   TEXT NOT NULL,
+				response    TEXT,
+				created_at  TEXT NOT NULL
+			)`,
+			`CREATE INDEX vtpass_transaction_events_request_id
+				ON vtpass_transaction_events (request_id, id)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`ALTER TABLE vtpass_transactions ADD COLUMN submitted_at TEXT`,
+		},
+	},
+}
+
+// Migrate creates or upgrades the schema. It is safe to call on every
+// start-up.
+func (s *Store) Migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS vtpass_schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := s.db.QueryContext(ctx, `SELECT version FROM vtpass_schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := s.apply(ctx, m); err != nil {
+			return fmt.Errorf("applying migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) apply(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.statements {
+		stmt = strings.ReplaceAll(stmt, "{{id}}", s.dialect.autoIncrement())
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO vtpass_schema_migrations (version, applied_at) VALUES (?, ?)`),
+		m.version, formatTime(time.Now())); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withTx runs fn in a transaction, committing if it returns nil.
+func (s *Store) withTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
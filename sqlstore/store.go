@@ -0,0 +1,378 @@
+// Package sqlstore implements a durable vtpass TransactionStore on top of
+// database/sql. It runs on Postgres and SQLite.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+)
+
+// Dialect selects the SQL flavour of the database.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+func (d Dialect) autoIncrement() string {
+	if d == Postgres {
+		return "BIGSERIAL PRIMARY KEY"
+	}
+	return "INTEGER PRIMARY KEY AUTOINCREMENT"
+}
+
+// timeLayout has a fixed width so that stored timestamps sort
+// chronologically as text in every dialect.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(timeLayout, value)
+}
+
+// StatusEvent is one recorded change of a transaction's status.
+type StatusEvent struct {
+	RequestID  string
+	FromStatus vt.TransactionStatus
+	ToStatus   vt.TransactionStatus
+	Response   json.RawMessage
+	CreatedAt  time.Time
+}
+
+// Store is a vt.TransactionStore backed by a SQL database. Every status
+// change is also appended to an event table.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+var (
+	_ vt.OutboxStore      = (*Store)(nil)
+	_ vt.IdempotencyStore = (*Store)(nil)
+)
+
+// New returns a Store using db. Call Migrate before first use.
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{db: db, dialect: dialect}
+}
+
+// rebind converts ? placeholders to the dialect's syntax.
+func (s *Store) rebind(query string) string {
+	if s.dialect != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+const selectColumns = `request_id, idempotency_key, service_id, billers_code, variation_code,
+	phone, amount, status, payload, response, txn, created_at, updated_at, submitted_at`
+
+func (s *Store) Save(ctx context.Context, record vt.TransactionRecord) error {
+	txn, err := json.Marshal(record.Transaction)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		lock := ""
+		if s.dialect == Postgres {
+			lock = " FOR UPDATE"
+		}
+
+		var previous vt.TransactionStatus
+		err := tx.QueryRowContext(ctx, s.rebind(`SELECT status FROM vtpass_transactions WHERE request_id = ?`+lock),
+			record.RequestID).Scan(&previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err := vt.ValidateTransition(previous, record.Status); err != nil {
+			return err
+		}
+		if record.IdempotencyKey != "" {
+			var owner string
+			keyErr := tx.QueryRowContext(ctx, s.rebind(`SELECT request_id FROM vtpass_transactions WHERE idempotency_key = ?`),
+				record.IdempotencyKey).Scan(&owner)
+			switch {
+			case keyErr == nil && owner != record.RequestID:
+				return vt.ErrDuplicateIdempotencyKey
+			case keyErr != nil && !errors.Is(keyErr, sql.ErrNoRows):
+				return keyErr
+			}
+		}
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			createdAt := record.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = now
+			}
+			_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO vtpass_transactions (`+selectColumns+`)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+				record.RequestID, nullString(record.IdempotencyKey), record.ServiceID, record.BillersCode,
+				record.VariationCode, record.Phone, record.Amount, record.Status, nullRaw(record.Payload),
+				nullRaw(record.Response), string(txn), formatTime(createdAt), formatTime(now),
+				nullTime(record.SubmittedAt))
+		case err == nil:
+			_, err = tx.ExecContext(ctx, s.rebind(`UPDATE vtpass_transactions SET
+				idempotency_key = ?, service_id = ?, billers_code = ?, variation_code = ?, phone = ?,
+				amount = ?, status = ?, payload = ?, response = ?, txn = ?, updated_at = ?,
+				submitted_at = COALESCE(submitted_at, ?)
+				WHERE request_id = ?`),
+				nullString(record.IdempotencyKey), record.ServiceID, record.BillersCode, record.VariationCode,
+				record.Phone, record.Amount, record.Status, nullRaw(record.Payload), nullRaw(record.Response),
+				string(txn), formatTime(now), nullTime(record.SubmittedAt), record.RequestID)
+		}
+		if isIdempotencyKeyConflict(err) {
+			// Another transaction took the key after the check above.
+			return vt.ErrDuplicateIdempotencyKey
+		}
+		if err != nil {
+			return err
+		}
+
+		if previous == record.Status {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO vtpass_transaction_events
+			(request_id, from_status, to_status, response, created_at) VALUES (?, ?, ?, ?, ?)`),
+			record.RequestID, previous, record.Status, nullRaw(record.Response), formatTime(now))
+		return err
+	})
+}
+
+func (s *Store) Get(ctx context.Context, requestID string) (*vt.TransactionRecord, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+selectColumns+` FROM vtpass_transactions WHERE request_id = ?`), requestID)
+	record, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, vt.ErrTransactionNotFound
+	}
+	return record, err
+}
+
+// MarkSubmitted claims a record for submission. Only the first caller for a
+// given request ID gets true.
+func (s *Store) MarkSubmitted(ctx context.Context, requestID string) (bool, error) {
+	now := formatTime(time.Now())
+	result, err := s.db.ExecContext(ctx, s.rebind(`UPDATE vtpass_transactions
+		SET submitted_at = ?, updated_at = ?
+		WHERE request_id = ? AND submitted_at IS NULL`), now, now, requestID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		return true, nil
+	}
+
+	if _, err := s.Get(ctx, requestID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// isIdempotencyKeyConflict reports whether err is a violation of the
+// unique index on idempotency_key. Drivers do not share an error type, so
+// the message is matched; Postgres names the index and SQLite the column.
+func isIdempotencyKeyConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "idempotency_key")
+}
+
+// GetByIdempotencyKey returns the record saved with key.
+func (s *Store) GetByIdempotencyKey(ctx context.Context, key string) (*vt.TransactionRecord, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+selectColumns+` FROM vtpass_transactions WHERE idempotency_key = ?`), key)
+	record, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, vt.ErrTransactionNotFound
+	}
+	return record, err
+}
+
+func (s *Store) List(ctx context.Context, filter vt.TransactionFilter) (*vt.TransactionPage, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, formatTime(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, formatTime(filter.To))
+	}
+	if filter.ServiceID != "" {
+		where = append(where, "service_id = ?")
+		args = append(args, filter.ServiceID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Phone != "" {
+		where = append(where, "phone = ?")
+		args = append(args, filter.Phone)
+	}
+	if filter.Cursor != "" {
+		cursor, err := vt.ParseTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		createdAt := formatTime(cursor.CreatedAt)
+		where = append(where, "(created_at < ? OR (created_at = ? AND request_id < ?))")
+		args = append(args, createdAt, createdAt, cursor.RequestID)
+	}
+
+	query := `SELECT ` + selectColumns + ` FROM vtpass_transactions`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := filter.PageSize()
+	query += " ORDER BY created_at DESC, request_id DESC LIMIT " + strconv.Itoa(limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &vt.TransactionPage{Transactions: make([]vt.TransactionRecord, 0)}
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Transactions = append(page.Transactions, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Transactions) > limit {
+		page.Transactions = page.Transactions[:limit]
+		page.NextCursor = vt.CursorAfter(page.Transactions[limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// Events returns the status history of a transaction, oldest first.
+func (s *Store) Events(ctx context.Context, requestID string) ([]StatusEvent, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT request_id, from_status, to_status, response, created_at
+		FROM vtpass_transaction_events WHERE request_id = ? ORDER BY id`), requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []StatusEvent
+	for rows.Next() {
+		var (
+			event     StatusEvent
+			response  sql.NullString
+			createdAt string
+		)
+		if err := rows.Scan(&event.RequestID, &event.FromStatus, &event.ToStatus, &response, &createdAt); err != nil {
+			return nil, err
+		}
+		if response.Valid {
+			event.Response = json.RawMessage(response.String)
+		}
+		if event.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(row scanner) (*vt.TransactionRecord, error) {
+	var (
+		record                 vt.TransactionRecord
+		idempotencyKey         sql.NullString
+		payload, response, txn sql.NullString
+		createdAt, updatedAt   string
+		submittedAt            sql.NullString
+	)
+
+	if err := row.Scan(&record.RequestID, &idempotencyKey, &record.ServiceID, &record.BillersCode,
+		&record.VariationCode, &record.Phone, &record.Amount, &record.Status, &payload, &response,
+		&txn, &createdAt, &updatedAt, &submittedAt); err != nil {
+		return nil, err
+	}
+
+	record.IdempotencyKey = idempotencyKey.String
+	if payload.Valid {
+		record.Payload = json.RawMessage(payload.String)
+	}
+	if response.Valid {
+		record.Response = json.RawMessage(response.String)
+	}
+	if txn.Valid && txn.String != "" {
+		if err := json.Unmarshal([]byte(txn.String), &record.Transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if record.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if submittedAt.Valid {
+		t, err := parseTime(submittedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		record.SubmittedAt = &t
+	}
+	return &record, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value *time.Time) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*value), Valid: true}
+}
+
+func nullRaw(value json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(value), Valid: len(value) > 0}
+}
@@ -0,0 +1,171 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	vt "github.com/CeoFred/vtpass-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestStore(t *testing.T) *Store {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store := New(db, SQLite)
+	require.NoError(t, store.Migrate(context.Background()))
+	// Migrations are idempotent.
+	require.NoError(t, store.Migrate(context.Background()))
+	return store
+}
+
+func TestSaveAndGet(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, vt.ErrTransactionNotFound)
+
+	record := vt.TransactionRecord{
+		RequestID:      "r1",
+		IdempotencyKey: "order-1",
+		ServiceID:      "mtn",
+		Phone:          "08011111111",
+		Amount:         100,
+		Status:         "initiated",
+		Payload:        json.RawMessage(`{"request_id":"r1"}`),
+	}
+	require.NoError(t, store.Save(ctx, record))
+
+	record.Status = "delivered"
+	record.Response = json.RawMessage(`{"code":"000"}`)
+	record.Transaction.TransactionID = "1700000000001"
+	require.NoError(t, store.Save(ctx, record))
+
+	saved, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, vt.StatusDelivered, saved.Status)
+	assert.Equal(t, "order-1", saved.IdempotencyKey)
+	assert.Equal(t, "1700000000001", saved.Transaction.TransactionID)
+	assert.JSONEq(t, `{"request_id":"r1"}`, string(saved.Payload))
+	assert.JSONEq(t, `{"code":"000"}`, string(saved.Response))
+	assert.False(t, saved.CreatedAt.IsZero())
+
+	byKey, err := store.GetByIdempotencyKey(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", byKey.RequestID)
+
+	events, err := store.Events(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, vt.TransactionStatus(""), events[0].FromStatus)
+	assert.Equal(t, vt.TransactionStatus("initiated"), events[0].ToStatus)
+	assert.Equal(t, vt.TransactionStatus("initiated"), events[1].FromStatus)
+	assert.Equal(t, vt.TransactionStatus("delivered"), events[1].ToStatus)
+	assert.JSONEq(t, `{"code":"000"}`, string(events[1].Response))
+
+	// A second record may not reuse the idempotency key.
+	err = store.Save(ctx, vt.TransactionRecord{RequestID: "r2", IdempotencyKey: "order-1", ServiceID: "mtn", Status: "initiated"})
+	assert.ErrorIs(t, err, vt.ErrDuplicateIdempotencyKey)
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	base := time.Date(2024, 12, 23, 10, 0, 0, 0, time.UTC)
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		service := "mtn"
+		if i%2 == 1 {
+			service = "dstv"
+		}
+		require.NoError(t, store.Save(ctx, vt.TransactionRecord{
+			RequestID: id,
+			ServiceID: service,
+			Phone:     "08011111111",
+			Status:    "delivered",
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	var ids []string
+	filter := vt.TransactionFilter{Limit: 2}
+	for {
+		page, err := store.List(ctx, filter)
+		require.NoError(t, err)
+		for _, record := range page.Transactions {
+			ids = append(ids, record.RequestID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, ids)
+
+	page, err := store.List(ctx, vt.TransactionFilter{
+		ServiceID: "mtn",
+		From:      base.Add(time.Minute),
+		To:        base.Add(4 * time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, "c", page.Transactions[0].RequestID)
+	assert.Equal(t, base.Add(2*time.Minute), page.Transactions[0].CreatedAt)
+}
+
+func TestRebind(t *testing.T) {
+	store := New(nil, Postgres)
+	assert.Equal(t, "SELECT a FROM t WHERE b = $1 AND c = $2", store.rebind("SELECT a FROM t WHERE b = ? AND c = ?"))
+	assert.Equal(t, "WHERE b = ?", New(nil, SQLite).rebind("WHERE b = ?"))
+}
+
+func TestMarkSubmitted(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	_, err := store.MarkSubmitted(ctx, "missing")
+	assert.ErrorIs(t, err, vt.ErrTransactionNotFound)
+
+	require.NoError(t, store.Save(ctx, vt.TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: "initiated"}))
+
+	claimed, err := store.MarkSubmitted(ctx, "r1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = store.MarkSubmitted(ctx, "r1")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Saving without SubmittedAt keeps the claim.
+	require.NoError(t, store.Save(ctx, vt.TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: "delivered"}))
+	saved, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	require.NotNil(t, saved.SubmittedAt)
+	assert.WithinDuration(t, time.Now(), *saved.SubmittedAt, time.Minute)
+}
+
+func TestSaveRejectsInvalidTransition(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	require.NoError(t, store.Save(ctx, vt.TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: vt.StatusDelivered}))
+	err := store.Save(ctx, vt.TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: vt.StatusPending})
+	assert.ErrorIs(t, err, vt.ErrInvalidTransition)
+
+	saved, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, vt.StatusDelivered, saved.Status)
+
+	events, err := store.Events(ctx, "r1")
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,100 @@
+package vtupass_go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is returned when a status update would move a
+// transaction backwards, e.g. a pending requery arriving after delivery.
+var ErrInvalidTransition = errors.New("invalid transaction status transition")
+
+// ErrUpdateNotConfirmed is returned when a transaction update is not
+// confirmed by requerying the purchase, e.g. a forged update or one for a
+// purchase VTPass still reports pending.
+var ErrUpdateNotConfirmed = errors.New("transaction update not confirmed")
+
+// TransactionStatus is the state of a purchase as reported by VTPass.
+type TransactionStatus string
+
+const (
+	// StatusInitiated is a purchase recorded but not yet confirmed by VTPass.
+	StatusInitiated TransactionStatus = "initiated"
+	StatusPending   TransactionStatus = "pending"
+	StatusDelivered TransactionStatus = "delivered"
+	StatusFailed    TransactionStatus = "failed"
+	// StatusReversed is a purchase VTPass refunded to the wallet.
+	StatusReversed TransactionStatus = "reversed"
+)
+
+// transitions lists the statuses each status may move to. Staying in the
+// same status is always allowed.
+var transitions = map[TransactionStatus][]TransactionStatus{
+	"":              {StatusInitiated, StatusPending, StatusDelivered, StatusFailed, StatusReversed},
+	StatusInitiated: {StatusPending, StatusDelivered, StatusFailed, StatusReversed},
+	StatusPending:   {StatusDelivered, StatusFailed, StatusReversed},
+	StatusDelivered: {StatusReversed},
+	StatusFailed:    {},
+	StatusReversed:  {},
+}
+
+// IsValid reports whether s is a status VTPass documents.
+func (s TransactionStatus) IsValid() bool {
+	_, ok := transitions[s]
+	return ok && s != ""
+}
+
+// IsFinal reports whether VTPass has settled the purchase. A delivered
+// purchase can still be reversed.
+func (s TransactionStatus) IsFinal() bool {
+	switch s {
+	case StatusDelivered, StatusFailed, StatusReversed:
+		return true
+	}
+	return false
+}
+
+// IsSuccess reports whether the purchase was delivered.
+func (s TransactionStatus) IsSuccess() bool {
+	return s == StatusDelivered
+}
+
+// CanTransitionTo reports whether a transaction in status s may move to
+// next. The empty status is the state of a transaction not yet stored.
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	if s == next {
+		return next.IsValid()
+	}
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an error wrapping ErrInvalidTransition when
+// from may not move to to.
+func ValidateTransition(from, to TransactionStatus) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %q to %q", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// Status returns the purchase's transaction status, derived from the
+// response code when VTPass leaves it out. A response that does not settle
+// the purchase is pending until requeried.
+func (r PayResponse) Status() TransactionStatus {
+	if status := transactionStatus(r.Code, r.Content.Transactions.Status); status != "" {
+		return status
+	}
+	return StatusPending
+}
+
+// Status returns the requeried transaction's status, derived from the
+// response code when VTPass leaves it out. It is empty when the response
+// does not settle the purchase, e.g. for 015 while VTPass cannot see it.
+func (r TransactionResponse) Status() TransactionStatus {
+	return transactionStatus(r.Code, r.Content.Transactions.Status)
+}
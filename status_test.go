@@ -0,0 +1,108 @@
+package vtupass_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to TransactionStatus
+		allowed  bool
+	}{
+		{"", StatusInitiated, true},
+		{StatusInitiated, StatusPending, true},
+		{StatusInitiated, StatusDelivered, true},
+		{StatusPending, StatusPending, true},
+		{StatusPending, StatusFailed, true},
+		{StatusDelivered, StatusReversed, true},
+		{StatusDelivered, StatusPending, false},
+		{StatusDelivered, StatusFailed, false},
+		{StatusFailed, StatusDelivered, false},
+		{StatusReversed, StatusDelivered, false},
+		{StatusPending, StatusInitiated, false},
+		{StatusPending, "processing", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to), "%q to %q", tt.from, tt.to)
+		if !tt.allowed {
+			assert.ErrorIs(t, ValidateTransition(tt.from, tt.to), ErrInvalidTransition)
+		}
+	}
+
+	assert.True(t, StatusDelivered.IsSuccess())
+	assert.False(t, StatusReversed.IsSuccess())
+	assert.True(t, StatusReversed.IsFinal())
+	assert.False(t, StatusPending.IsFinal())
+	assert.False(t, TransactionStatus("").IsValid())
+}
+
+func TestApplyTransactionUpdateRejectsOutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	var current TransactionStatus
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		var txn TransactionResponse
+		txn.Code = TRANSACTION_SUCCESSFUL
+		txn.Content.Transactions.Status = current
+		return jsonResponse(http.StatusOK, txn), nil
+	})
+	service := &VTService{client: mockClient, store: store}
+	require.NoError(t, store.Save(ctx, TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: StatusPending}))
+
+	// VTPass reports status; the update's own status is not trusted.
+	update := func(status TransactionStatus) TransactionUpdate {
+		current = status
+		var u TransactionUpdate
+		u.Type = "transaction-update"
+		u.Data.RequestID = "r1"
+		u.Data.Code = TRANSACTION_SUCCESSFUL
+		u.Data.Content.Transactions.Status = StatusDelivered
+		return u
+	}
+
+	record, err := service.ApplyTransactionUpdate(ctx, update(StatusDelivered))
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, record.Status)
+
+	_, err = service.ApplyTransactionUpdate(ctx, update(StatusPending))
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	record, err = store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, record.Status)
+
+	record, err = service.ApplyTransactionUpdate(ctx, update(StatusReversed))
+	require.NoError(t, err)
+	assert.Equal(t, StatusReversed, record.Status)
+}
+
+func TestApplyTransactionUpdateIgnoresForgedStatus(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, TransactionResponse{Code: INVALID_REQUEST_ID}), nil
+	})
+	service := &VTService{client: mockClient, store: store}
+	require.NoError(t, store.Save(ctx, TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: StatusPending}))
+
+	var forged TransactionUpdate
+	forged.Data.RequestID = "r1"
+	forged.Data.Code = TRANSACTION_SUCCESSFUL
+	forged.Data.Content.Transactions.Status = StatusDelivered
+	_, err := service.ApplyTransactionUpdate(ctx, forged)
+	assert.Error(t, err)
+
+	record, err := store.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, record.Status)
+}
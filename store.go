@@ -0,0 +1,289 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNoTransactionStore  = errors.New("no transaction store configured")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	// ErrDuplicateIdempotencyKey is returned by Save when another record
+	// already holds the idempotency key.
+	ErrDuplicateIdempotencyKey = errors.New("idempotency key already used by another transaction")
+)
+
+// TransactionRecord is a purchase as kept by a TransactionStore.
+type TransactionRecord struct {
+	RequestID      string            `json:"request_id"`
+	IdempotencyKey string            `json:"idempotency_key,omitempty"`
+	ServiceID      string            `json:"service_id"`
+	BillersCode    string            `json:"billers_code,omitempty"`
+	VariationCode  string            `json:"variation_code,omitempty"`
+	Phone          string            `json:"phone"`
+	Amount         float64           `json:"amount"`
+	Status         TransactionStatus `json:"status"`
+	Payload        json.RawMessage   `json:"payload,omitempty"`
+	Response       json.RawMessage   `json:"response,omitempty"`
+	Transaction    Transaction       `json:"transaction"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	// SubmittedAt is set once the purchase has been claimed for sending to
+	// VTPass, by an Outbox or by Purchase.
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+}
+
+// IsFinal reports whether VTPass has settled the record's purchase.
+func (r TransactionRecord) IsFinal() bool {
+	return r.Status.IsFinal()
+}
+
+// TransactionFilter selects records from a TransactionStore. Zero fields
+// match everything.
+type TransactionFilter struct {
+	// From and To bound CreatedAt, inclusive of From and exclusive of To.
+	From      time.Time
+	To        time.Time
+	ServiceID string
+	Status    TransactionStatus
+	Phone     string
+	// Limit is the page size. Defaults to 50.
+	Limit int
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	// Refresh requeries records that are not final before returning them.
+	Refresh bool
+}
+
+// Matches reports whether record passes every field of the filter except
+// the cursor and limit.
+func (f TransactionFilter) Matches(record TransactionRecord) bool {
+	if !f.From.IsZero() && record.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.CreatedAt.Before(f.To) {
+		return false
+	}
+	if f.ServiceID != "" && record.ServiceID != f.ServiceID {
+		return false
+	}
+	if f.Status != "" && record.Status != f.Status {
+		return false
+	}
+	if f.Phone != "" && record.Phone != f.Phone {
+		return false
+	}
+	return true
+}
+
+// PageSize returns Limit or its default.
+func (f TransactionFilter) PageSize() int {
+	if f.Limit <= 0 {
+		return 50
+	}
+	return f.Limit
+}
+
+// TransactionPage is one page of records, newest first.
+type TransactionPage struct {
+	Transactions []TransactionRecord
+	// NextCursor fetches the following page. It is empty on the last page.
+	NextCursor string
+}
+
+// TransactionCursor marks a position in the newest-first ordering of
+// records. Stores use it for keyset pagination.
+type TransactionCursor struct {
+	CreatedAt time.Time `json:"t"`
+	RequestID string    `json:"id"`
+}
+
+// CursorAfter returns the cursor positioned after record.
+func CursorAfter(record TransactionRecord) TransactionCursor {
+	return TransactionCursor{CreatedAt: record.CreatedAt, RequestID: record.RequestID}
+}
+
+func (c TransactionCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Precedes reports whether the cursor comes before record in newest-first
+// order, i.e. whether record belongs on a later page.
+func (c TransactionCursor) Precedes(record TransactionRecord) bool {
+	if !record.CreatedAt.Equal(c.CreatedAt) {
+		return record.CreatedAt.Before(c.CreatedAt)
+	}
+	return record.RequestID < c.RequestID
+}
+
+// ParseTransactionCursor decodes a cursor produced by Encode.
+func ParseTransactionCursor(value string) (TransactionCursor, error) {
+	var c TransactionCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return c, nil
+}
+
+// TransactionStore persists purchases made through a VTService.
+type TransactionStore interface {
+	// Save inserts the record or replaces the one with the same RequestID.
+	// A SubmittedAt already stored is kept. Updates whose status may not
+	// follow the stored one return an error wrapping ErrInvalidTransition,
+	// and records whose IdempotencyKey belongs to another RequestID return
+	// ErrDuplicateIdempotencyKey.
+	Save(ctx context.Context, record TransactionRecord) error
+	// Get returns ErrTransactionNotFound for unknown request IDs.
+	Get(ctx context.Context, requestID string) (*TransactionRecord, error)
+	// List returns records matching filter, newest first.
+	List(ctx context.Context, filter TransactionFilter) (*TransactionPage, error)
+}
+
+// OutboxStore is a TransactionStore that can atomically claim a record for
+// submission.
+type OutboxStore interface {
+	TransactionStore
+	// MarkSubmitted sets SubmittedAt if it is unset and reports whether
+	// this call set it.
+	MarkSubmitted(ctx context.Context, requestID string) (bool, error)
+}
+
+// IdempotencyStore is a TransactionStore that can look records up by the
+// caller's idempotency key.
+type IdempotencyStore interface {
+	TransactionStore
+	// GetByIdempotencyKey returns ErrTransactionNotFound for unknown keys.
+	GetByIdempotencyKey(ctx context.Context, key string) (*TransactionRecord, error)
+}
+
+// MemoryTransactionStore keeps records in memory. It is safe for
+// concurrent use.
+type MemoryTransactionStore struct {
+	mu      sync.RWMutex
+	records map[string]TransactionRecord
+	// keys maps idempotency keys to request IDs.
+	keys map[string]string
+}
+
+func NewMemoryTransactionStore() *MemoryTransactionStore {
+	return &MemoryTransactionStore{records: make(map[string]TransactionRecord), keys: make(map[string]string)}
+}
+
+func (m *MemoryTransactionStore) Save(ctx context.Context, record TransactionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	existing, ok := m.records[record.RequestID]
+	if err := ValidateTransition(existing.Status, record.Status); err != nil {
+		return err
+	}
+	if key := record.IdempotencyKey; key != "" {
+		if owner, taken := m.keys[key]; taken && owner != record.RequestID {
+			return ErrDuplicateIdempotencyKey
+		}
+	}
+	if ok {
+		record.CreatedAt = existing.CreatedAt
+		if record.SubmittedAt == nil {
+			record.SubmittedAt = existing.SubmittedAt
+		}
+	} else if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	record.UpdatedAt = now
+
+	if existing.IdempotencyKey != record.IdempotencyKey {
+		delete(m.keys, existing.IdempotencyKey)
+	}
+	if record.IdempotencyKey != "" {
+		m.keys[record.IdempotencyKey] = record.RequestID
+	}
+	m.records[record.RequestID] = record
+	return nil
+}
+
+func (m *MemoryTransactionStore) Get(ctx context.Context, requestID string) (*TransactionRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.records[requestID]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return &record, nil
+}
+
+func (m *MemoryTransactionStore) MarkSubmitted(ctx context.Context, requestID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[requestID]
+	if !ok {
+		return false, ErrTransactionNotFound
+	}
+	if record.SubmittedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	record.SubmittedAt = &now
+	record.UpdatedAt = now
+	m.records[requestID] = record
+	return true, nil
+}
+
+func (m *MemoryTransactionStore) GetByIdempotencyKey(ctx context.Context, key string) (*TransactionRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	requestID, ok := m.keys[key]
+	if !ok || key == "" {
+		return nil, ErrTransactionNotFound
+	}
+	record := m.records[requestID]
+	return &record, nil
+}
+
+func (m *MemoryTransactionStore) List(ctx context.Context, filter TransactionFilter) (*TransactionPage, error) {
+	var cursor *TransactionCursor
+	if filter.Cursor != "" {
+		c, err := ParseTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+
+	m.mu.RLock()
+	matched := make([]TransactionRecord, 0)
+	for _, record := range m.records {
+		if filter.Matches(record) && (cursor == nil || cursor.Precedes(record)) {
+			matched = append(matched, record)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return CursorAfter(matched[i]).Precedes(matched[j])
+	})
+
+	page := &TransactionPage{Transactions: matched}
+	if limit := filter.PageSize(); len(matched) > limit {
+		page.Transactions = matched[:limit]
+		page.NextCursor = CursorAfter(matched[limit-1]).Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,294 @@
+package vtupass_go
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+)
+
+// ExportFormat selects the output of ExportTransactions.
+type ExportFormat string
+
+const (
+	ExportCSV       ExportFormat = "csv"
+	ExportJSONLines ExportFormat = "jsonl"
+)
+
+const exportPageLimit = 500
+
+// Transactions lists purchases recorded in the transaction store. With
+// filter.Refresh set, records that are not final are requeried and the
+// store updated before they are returned.
+func (s *VTService) Transactions(ctx context.Context, filter TransactionFilter) (*TransactionPage, error) {
+	if s.store == nil {
+		return nil, ErrNoTransactionStore
+	}
+
+	page, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Refresh {
+		for i, record := range page.Transactions {
+			if record.IsFinal() {
+				continue
+			}
+			if refreshed, err := s.refreshTransaction(ctx, record); err == nil {
+				page.Transactions[i] = *refreshed
+			}
+		}
+	}
+
+	return page, nil
+}
+
+// Transaction returns the recorded purchase with requestID. A record that is
+// not final is requeried first.
+func (s *VTService) Transaction(ctx context.Context, requestID string) (*TransactionRecord, error) {
+	if s.store == nil {
+		return nil, ErrNoTransactionStore
+	}
+
+	record, err := s.store.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.IsFinal() {
+		if refreshed, err := s.refreshTransaction(ctx, *record); err == nil {
+			return refreshed, nil
+		}
+	}
+	return record, nil
+}
+
+// TransactionByIdempotencyKey returns the recorded purchase made with the
+// caller's idempotency key. It returns ErrNoTransactionStore unless the
+// store implements IdempotencyStore.
+func (s *VTService) TransactionByIdempotencyKey(ctx context.Context, key string) (*TransactionRecord, error) {
+	store, ok := s.store.(IdempotencyStore)
+	if !ok {
+		return nil, ErrNoTransactionStore
+	}
+	return store.GetByIdempotencyKey(ctx, key)
+}
+
+// refreshTransaction requeries record and saves the result.
+func (s *VTService) refreshTransaction(ctx context.Context, record TransactionRecord) (*TransactionRecord, error) {
+	txn, err := s.QueryTransaction(ctx, record.RequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := transactionStatus(txn.Code, txn.Content.Transactions.Status)
+	if status == "" {
+		// Not settled or not yet visible to VTPass.
+		return &record, nil
+	}
+
+	record.Status = status
+	record.Transaction = txn.Content.Transactions
+	if err := s.store.Save(ctx, record); err != nil {
+		return nil, err
+	}
+	return s.store.Get(ctx, record.RequestID)
+}
+
+// ApplyTransactionUpdate saves a transaction-update webhook to the
+// transaction store. The update only says which purchase changed: its
+// status is confirmed with QueryTransaction and the requeried status is
+// saved, so a forged or replayed update cannot settle a purchase. When the
+// requery reports no new status the update returns an error wrapping
+// ErrUpdateNotConfirmed. An update that may not follow the stored status,
+// e.g. pending arriving after delivered, returns an error wrapping
+// ErrInvalidTransition. Either way the record is left unchanged.
+func (s *VTService) ApplyTransactionUpdate(ctx context.Context, update TransactionUpdate) (*TransactionRecord, error) {
+	if s.store == nil {
+		return nil, ErrNoTransactionStore
+	}
+
+	record, err := s.store.Get(ctx, update.Data.RequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := s.QueryTransaction(ctx, record.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	status := txn.Status()
+	if status == "" || status == record.Status {
+		return nil, fmt.Errorf("%w: transaction %s is %s after requery (code %q)", ErrUpdateNotConfirmed, record.RequestID, record.Status, txn.Code)
+	}
+
+	record.Status = status
+	record.Transaction = txn.Content.Transactions
+	record.Response = txn.Raw
+	if err := s.store.Save(ctx, *record); err != nil {
+		return nil, err
+	}
+	return s.store.Get(ctx, record.RequestID)
+}
+
+// ExportTransactions writes every record matching filter to w. The filter's
+// Cursor and Limit are ignored; all pages are exported.
+func (s *VTService) ExportTransactions(ctx context.Context, w io.Writer, format ExportFormat, filter TransactionFilter) error {
+	var write func(TransactionRecord) error
+	var flush func() error
+
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		write = func(record TransactionRecord) error { return cw.Write(csvRow(record)) }
+		flush = func() error { cw.Flush(); return cw.Error() }
+	case ExportJSONLines:
+		enc := json.NewEncoder(w)
+		write = func(record TransactionRecord) error { return enc.Encode(record) }
+		flush = func() error { return nil }
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	filter.Cursor = ""
+	filter.Limit = exportPageLimit
+	for {
+		page, err := s.Transactions(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, record := range page.Transactions {
+			if err := write(record); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return flush()
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+var csvHeader = []string{
+	"request_id", "created_at", "updated_at", "status", "service_id", "billers_code",
+	"variation_code", "phone", "amount", "transactionId", "product_name", "unique_element",
+	"type", "quantity", "unit_price", "convenience_fee", "total_amount", "channel",
+	"platform", "email", "name", "wallet_credit_id", "commission", "discount",
+}
+
+func csvRow(record TransactionRecord) []string {
+	txn := record.Transaction
+	name := ""
+	if txn.Name != nil {
+		name = *txn.Name
+	}
+
+	return []string{
+		record.RequestID,
+		record.CreatedAt.Format(time.RFC3339),
+		record.UpdatedAt.Format(time.RFC3339),
+		string(record.Status),
+		record.ServiceID,
+		record.BillersCode,
+		record.VariationCode,
+		record.Phone,
+		strconv.FormatFloat(record.Amount, 'f', -1, 64),
+		txn.TransactionID,
+		txn.ProductName,
+		txn.UniqueElement,
+		txn.Type,
+		strconv.FormatFloat(txn.Quantity.Float64(), 'f', -1, 64),
+		strconv.FormatFloat(txn.UnitPrice.Float64(), 'f', -1, 64),
+		strconv.FormatFloat(txn.ConvenienceFee.Float64(), 'f', -1, 64),
+		strconv.FormatFloat(txn.TotalAmount.Float64(), 'f', -1, 64),
+		txn.Channel,
+		txn.Platform,
+		txn.Email,
+		name,
+		txn.WalletCreditID,
+		strconv.FormatFloat(txn.Commission.Float64(), 'f', -1, 64),
+		strconv.FormatFloat(txn.Discount.Float64(), 'f', -1, 64),
+	}
+}
+
+// transactionStatus returns VTPass's transaction status, falling back to
+// one derived from the response code. It returns the empty status when
+// neither settles the purchase, e.g. for 015 on a requery that VTPass
+// cannot see yet or for codes it does not document, so callers keep the
+// status they have instead of failing a purchase still in flight.
+func transactionStatus(code string, status TransactionStatus) TransactionStatus {
+	if status != "" {
+		return status
+	}
+	switch {
+	case code == TRANSACTION_SUCCESSFUL:
+		return StatusDelivered
+	case code == TRANSACTION_PROCESSING:
+		return StatusPending
+	case code == TRANSACTION_FAILED, errorCodes[code] && code != REQUEST_ID_ALREADY_EXISTS:
+		return StatusFailed
+	}
+	return ""
+}
+
+// recordPurchase stores the intent to pay before the request is sent. An
+// intent already recorded, e.g. by an Outbox, is left untouched.
+func (s *VTService) recordPurchase(ctx context.Context, payload PurchaseRequest) error {
+	if s.store == nil {
+		return nil
+	}
+
+	if _, err := s.store.Get(ctx, payload.RequestID); err == nil {
+		return nil
+	} else if !errors.Is(err, ErrTransactionNotFound) {
+		return err
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Save(ctx, TransactionRecord{
+		RequestID:      payload.RequestID,
+		IdempotencyKey: payload.IdempotencyKey,
+		ServiceID:      payload.ServiceID,
+		BillersCode:    payload.BillersCode,
+		VariationCode:  payload.VariationCode,
+		Phone:          payload.Phone,
+		Amount:         payload.Amount,
+		Status:         StatusInitiated,
+		Payload:        raw,
+	})
+}
+
+// recordOutcome stores VTPass's response to a purchase. The purchase has
+// already happened, so store failures are logged rather than returned.
+func (s *VTService) recordOutcome(ctx context.Context, requestID string, status TransactionStatus, body []byte, txn *Transaction) {
+	if s.store == nil {
+		return
+	}
+
+	record, err := s.store.Get(ctx, requestID)
+	if err != nil {
+		log.Printf("loading transaction %s failed: %v", requestID, err)
+		return
+	}
+
+	record.Status = status
+	record.Response = body
+	if txn != nil {
+		record.Transaction = *txn
+	}
+	if err := s.store.Save(ctx, *record); err != nil {
+		log.Printf("saving transaction %s failed: %v", requestID, err)
+	}
+}
@@ -0,0 +1,179 @@
+package vtupass_go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"testing"
+
+	httpclient "github.com/CeoFred/vtpass-go/lib"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionsListAndExport(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		if path == "requery" {
+			var txn TransactionResponse
+			txn.Code = TRANSACTION_SUCCESSFUL
+			txn.Content.Transactions.Status = "delivered"
+			return jsonResponse(http.StatusOK, txn), nil
+		}
+
+		req := payload.(PurchaseRequest)
+		var resp PayResponse
+		resp.Code = TRANSACTION_SUCCESSFUL
+		resp.Content.Transactions.Status = "delivered"
+		resp.Content.Transactions.ProductName = "MTN Airtime VTU"
+		resp.Content.Transactions.TotalAmount = FlexFloat(req.Amount)
+		if req.ServiceID == "dstv" {
+			resp.Code = TRANSACTION_PROCESSING
+			resp.Content.Transactions.Status = "pending"
+		}
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+
+	for _, req := range []PurchaseRequest{
+		{RequestID: "r1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"},
+		{RequestID: "r2", ServiceID: "mtn", Amount: 200, Phone: "08022222222"},
+		{RequestID: "r3", ServiceID: "dstv", BillersCode: "1212121212", Amount: 300, Phone: "08011111111"},
+	} {
+		_, err := service.Purchase(context.Background(), req)
+		assert.NoError(t, err)
+	}
+
+	page, err := service.Transactions(context.Background(), TransactionFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	assert.Equal(t, "r3", page.Transactions[0].RequestID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	next, err := service.Transactions(context.Background(), TransactionFilter{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, next.Transactions, 1)
+	assert.Empty(t, next.NextCursor)
+
+	seen := map[string]bool{}
+	for _, record := range append(page.Transactions, next.Transactions...) {
+		seen[record.RequestID] = true
+	}
+	assert.Len(t, seen, 3)
+
+	pending, err := service.Transactions(context.Background(), TransactionFilter{Status: "pending"})
+	assert.NoError(t, err)
+	assert.Len(t, pending.Transactions, 1)
+	assert.Equal(t, "r3", pending.Transactions[0].RequestID)
+
+	refreshed, err := service.Transactions(context.Background(), TransactionFilter{Phone: "08011111111", Refresh: true})
+	assert.NoError(t, err)
+	assert.Len(t, refreshed.Transactions, 2)
+	for _, record := range refreshed.Transactions {
+		assert.Equal(t, StatusDelivered, record.Status)
+	}
+
+	var csvOut bytes.Buffer
+	assert.NoError(t, service.ExportTransactions(context.Background(), &csvOut, ExportCSV, TransactionFilter{ServiceID: "mtn"}))
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, "MTN Airtime VTU", rows[1][10])
+
+	var jsonOut bytes.Buffer
+	assert.NoError(t, service.ExportTransactions(context.Background(), &jsonOut, ExportJSONLines, TransactionFilter{}))
+	lines := 0
+	scanner := bufio.NewScanner(&jsonOut)
+	for scanner.Scan() {
+		lines++
+	}
+	assert.Equal(t, 3, lines)
+}
+
+func TestRefreshKeepsUnsettledStatus(t *testing.T) {
+	requery := TransactionResponse{Code: INVALID_REQUEST_ID}
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, requery), nil
+	})
+
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+	ctx := context.Background()
+	assert.NoError(t, store.Save(ctx, TransactionRecord{RequestID: "r1", ServiceID: "mtn", Status: StatusPending}))
+
+	for _, code := range []string{INVALID_REQUEST_ID, "", "999"} {
+		requery.Code = code
+		record, err := service.Transaction(ctx, "r1")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusPending, record.Status, code)
+	}
+
+	requery.Code = TRANSACTION_SUCCESSFUL
+	record, err := service.Transaction(ctx, "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDelivered, record.Status)
+}
+
+func TestPurchaseRejectsReusedIdempotencyKey(t *testing.T) {
+	pays := 0
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		pays++
+		var resp PayResponse
+		resp.Code = TRANSACTION_SUCCESSFUL
+		resp.Content.Transactions.Status = "delivered"
+		return jsonResponse(http.StatusOK, resp), nil
+	})
+
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+
+	_, err := service.Purchase(ctx, PurchaseRequest{RequestID: "r1", IdempotencyKey: "order-1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	assert.NoError(t, err)
+	_, err = service.Purchase(ctx, PurchaseRequest{RequestID: "r2", IdempotencyKey: "order-1", ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	assert.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+	assert.Equal(t, 1, pays)
+
+	record, err := store.GetByIdempotencyKey(ctx, "order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", record.RequestID)
+}
+
+func TestPurchaseRefusalCodes(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return rawJSONResponse(http.StatusOK, `{"code":"`+payload.(PurchaseRequest).RequestID+`"}`), nil
+	})
+
+	ctx := context.Background()
+	store := NewMemoryTransactionStore()
+	service := &VTService{client: mockClient, store: store}
+
+	// The mock answers with the request ID as the code.
+	for _, code := range []string{BELOW_MINIMUM_AMOUNT, ABOVE_MAXIMUM_AMOUNT, LIKELY_DUPLICATE_TRANSACTION, ACCOUNT_SUSPENDED, IP_NOT_WHITELISTED, IMPROPER_REQUEST_ID, TRANSACTION_NOT_PROCESSED} {
+		_, err := service.Purchase(ctx, PurchaseRequest{RequestID: code, ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+		var errorResponse ErrorResponse
+		if assert.ErrorAs(t, err, &errorResponse, code) {
+			assert.Equal(t, code, errorResponse.Code)
+			assert.NotContains(t, errorResponse.Error(), "VTPASS ERROR", code)
+		}
+		record, err := store.Get(ctx, code)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, record.Status, code)
+	}
+
+	// A reused request ID may name a paid purchase, so it is not failed.
+	_, err := service.Purchase(ctx, PurchaseRequest{RequestID: REQUEST_ID_ALREADY_EXISTS, ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+	assert.True(t, IsDuplicateRequestID(err))
+	record, err := store.Get(ctx, REQUEST_ID_ALREADY_EXISTS)
+	assert.NoError(t, err)
+	assert.NotEqual(t, StatusFailed, record.Status)
+	assert.Empty(t, transactionStatus(REQUEST_ID_ALREADY_EXISTS, ""))
+}
@@ -1,5 +1,7 @@
 package vtupass_go
 
+import "encoding/json"
+
 type Environment string
 
 const (
@@ -15,9 +17,9 @@ type ServiceCategory struct {
 type Service struct {
 	ServiceID      string `json:"serviceID"`
 	Name           string `json:"name"`
-	MinimumAmount  string `json:"minimium_amount"`
-	MaximumAmount  uint   `json:"maximum_amount"`
-	ConvenienceFee string `json:"convinience_fee"`
+	MinimumAmount  FlexFloat  `json:"minimium_amount"`
+	MaximumAmount  FlexFloat  `json:"maximum_amount"`
+	ConvenienceFee FlexString `json:"convinience_fee"`
 	ProductType    string `json:"product_type"`
 	Image          string `json:"image"`
 }
@@ -25,7 +27,7 @@ type Service struct {
 type Variation struct {
 	VariationCode   string `json:"variation_code"`
 	Name            string `json:"name"`
-	VariationAmount string `json:"variation_amount"`
+	VariationAmount FlexFloat `json:"variation_amount"`
 	FixedPrice      string `json:"fixedPrice"`
 }
 
@@ -44,6 +46,15 @@ type CustomerInfo struct {
 	// MAXPurchaseAmount string `json:"Max_Purchase_Amount"`
 	// MinPurchaseAmount string `json:"Min_Purchase_Amount"`
 	CustomerPhone string `json:"Customer_Phone"`
+	// Fields returned for TV subscriptions.
+	Status         string    `json:"Status"`
+	DueDate        string    `json:"Due_Date"`
+	CustomerType   string    `json:"Customer_Type"`
+	CurrentBouquet string    `json:"Current_Bouquet"`
+	RenewalAmount  FlexFloat `json:"Renewal_Amount"`
+	// WrongBillersCode and Error are set when the customer ID is invalid.
+	WrongBillersCode bool   `json:"WrongBillersCode"`
+	Error            string `json:"error"`
 }
 
 type ElectricityPurchase struct {
@@ -55,12 +66,29 @@ type ElectricityPurchase struct {
 	Phone         string  `json:"phone"`
 }
 
+// PurchaseRequest is the payload accepted by the pay endpoint for every
+// service. Fields that do not apply to a service are left empty.
+type PurchaseRequest struct {
+	RequestID     string  `json:"request_id"`
+	ServiceID     string  `json:"serviceID"`
+	BillersCode   string  `json:"billersCode,omitempty"`
+	VariationCode string  `json:"variation_code,omitempty"`
+	Amount        float64 `json:"amount,omitempty"`
+	Phone         string  `json:"phone"`
+	// SubscriptionType is "change" or "renew" for TV subscriptions.
+	SubscriptionType string `json:"subscription_type,omitempty"`
+	Quantity         int    `json:"quantity,omitempty"`
+	// IdempotencyKey is the caller's own key for the purchase. It is
+	// stored with the transaction but not sent to VTPass.
+	IdempotencyKey string `json:"-"`
+}
+
 type Data struct {
 	Code                string  `json:"code"`
 	Content             Content `json:"content"`
 	ResponseDescription string  `json:"response_description"`
-	Amount              float64 `json:"amount"`
-	TransactionDate     *string `json:"transaction_date"`
+	Amount              FlexFloat `json:"amount"`
+	TransactionDate     TransactionDate `json:"transaction_date"`
 	RequestID           string  `json:"requestId"`
 	PurchasedCode       string  `json:"purchased_code"`
 }
@@ -70,23 +98,23 @@ type TransactionUpdate struct {
 	Data Data   `json:"data"`
 }
 type Transaction struct {
-	Amount              interface{}     `json:"amount"`
-	ConvenienceFee      interface{}     `json:"convenience_fee"`
-	Status              string      `json:"status"`
+	Amount              FlexFloat     `json:"amount"`
+	ConvenienceFee      FlexFloat     `json:"convenience_fee"`
+	Status              TransactionStatus `json:"status"`
 	Name                *string     `json:"name"`
 	Phone               string      `json:"phone"`
 	Email               string      `json:"email"`
 	Type                string      `json:"type"`
 	CreatedAt           string      `json:"created_at"`
-	// Discount            *string     `json:"discount"`
+	Discount            FlexFloat   `json:"discount"`
 	// GiftcardID          *string     `json:"giftcard_id"`
-	TotalAmount         float64     `json:"total_amount"`
-	// Commission          float64     `json:"commission"`
+	TotalAmount         FlexFloat     `json:"total_amount"`
+	Commission          FlexFloat   `json:"commission"`
 	Channel             string      `json:"channel"`
 	Platform            string      `json:"platform"`
 	ServiceVerification *string     `json:"service_verification"`
-	Quantity            float64     `json:"quantity"`
-	UnitPrice           interface{} `json:"unit_price"`
+	Quantity            FlexFloat     `json:"quantity"`
+	UnitPrice           FlexFloat `json:"unit_price"`
 	UniqueElement       string      `json:"unique_element"`
 	ProductName         string      `json:"product_name"`
 	TransactionID       string      `json:"transactionId"`
@@ -108,16 +136,18 @@ type PayResponse struct {
 	Content             Content          `json:"content"`
 	ResponseDescription string           `json:"response_description"`
 	RequestID           string           `json:"requestId"`
-	Amount              string           `json:"amount"`
-	// TransactionDate     TransactionDate  `json:"transaction_date"`
+	Amount              FlexFloat           `json:"amount"`
+	TransactionDate     TransactionDate  `json:"transaction_date"`
 	PurchasedCode       string           `json:"purchased_code"`
 	ExchangeReference   string           `json:"exchangeReference"`
 	ArrearsBalance      *float64         `json:"arrearsBalance"`
 	AppliedToArrears    *float64         `json:"appliedToArrears"`
 	Wallet              *float64         `json:"wallet"`
-	VAT                 string          `json:"vat"`
+	VAT                 FlexString          `json:"vat"`
 	InvoiceNumber       string           `json:"invoiceNumber"`
 	AppliedToWallet     *float64         `json:"appliedToWallet"`
-	Units               string          `json:"units"`
+	Units               FlexString          `json:"units"`
 	Token               string           `json:"token"`
+	Raw                 json.RawMessage  `json:"-"`
+	HTTPStatus          int              `json:"-"`
 }
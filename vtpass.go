@@ -3,11 +3,13 @@ package vtupass_go
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-
+	"io"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	httpclient "github.com/CeoFred/vtpass-go/lib"
@@ -16,18 +18,66 @@ import (
 )
 
 type VTService struct {
-	apiKey          string
-	publicKey       string
-	secretKey       string
-	client          HttpClient
-	authCredentials map[string]string
-	Enviroment      Environment
+	client        HttpClient
+	credentials   CredentialsProvider
+	Enviroment    Environment
+	baseURL       string
+	liveGuard     bool
+	liveUnlocked  bool
+	clientOptions []httpclient.Option
+	store         TransactionStore
+
+	markup         Markup
+	serviceMarkups map[string]Markup
+	quoteTTL       time.Duration
+
+	mu             sync.RWMutex
+	spendListeners []*spendListener
+}
+
+type spendListener struct {
+	fn func(amount Money, at time.Time)
+}
+
+// Option configures a VTService.
+type Option func(*VTService)
+
+// WithClientOptions passes options such as rate limits and concurrency caps
+// to the underlying httpclient.APIClient.
+func WithClientOptions(opts ...httpclient.Option) Option {
+	return func(s *VTService) {
+		s.clientOptions = append(s.clientOptions, opts...)
+	}
+}
+
+// WithTransactionStore records every purchase in store.
+func WithTransactionStore(store TransactionStore) Option {
+	return func(s *VTService) {
+		s.store = store
+	}
+}
+
+// WithCredentialsProvider queries provider for credentials on every
+// request instead of using the keys passed to NewVTService.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(s *VTService) {
+		s.credentials = provider
+	}
 }
 
 type BaseResponse struct {
 	Code string `json:"code"`
 }
 
+// ResponseMeta is the response a result was decoded from.
+type ResponseMeta struct {
+	// Raw is the response body as received, including fields that are
+	// not decoded.
+	Raw json.RawMessage `json:"-"`
+	// HTTPStatus is the status code of the response.
+	HTTPStatus int `json:"-"`
+}
+
 type ErrorResponse struct {
 	BaseResponse
 }
@@ -47,6 +97,44 @@ func (e ErrorResponse) Error() string {
 		message = "BILLER NOT REACHABLE AT THIS POINT"
 	case INVALID_CREDENTIALS:
 		message = "INVALID CREDENTIALS"
+	case TRANSACTION_FAILED:
+		message = "TRANSACTION FAILED"
+	case LOW_WALLET_BALANCE:
+		message = "LOW WALLET BALANCE"
+	case INVALID_REQUEST_ID:
+		message = "INVALID REQUEST ID"
+	case VARIATION_CODE_DOES_NOT_EXIST:
+		message = "VARIATION CODE DOES NOT EXIST"
+	case BELOW_MINIMUM_AMOUNT:
+		message = "BELOW MINIMUM AMOUNT ALLOWED"
+	case REQUEST_ID_ALREADY_EXISTS:
+		message = "REQUEST ID ALREADY EXISTS"
+	case ABOVE_MAXIMUM_AMOUNT:
+		message = "ABOVE MAXIMUM AMOUNT ALLOWED"
+	case LIKELY_DUPLICATE_TRANSACTION:
+		message = "LIKELY DUPLICATE TRANSACTION"
+	case ACCOUNT_LOCKED:
+		message = "ACCOUNT LOCKED"
+	case ACCOUNT_SUSPENDED:
+		message = "ACCOUNT SUSPENDED"
+	case API_ACCESS_NOT_ENABLED:
+		message = "API ACCESS NOT ENABLED"
+	case ACCOUNT_INACTIVE:
+		message = "ACCOUNT INACTIVE"
+	case RECIPIENT_BANK_INVALID:
+		message = "RECIPIENT BANK INVALID"
+	case RECIPIENT_ACCOUNT_NOT_VERIFIED:
+		message = "RECIPIENT ACCOUNT COULD NOT BE VERIFIED"
+	case IP_NOT_WHITELISTED:
+		message = "IP NOT WHITELISTED"
+	case PRODUCT_NOT_WHITELISTED:
+		message = "PRODUCT NOT WHITELISTED"
+	case IMPROPER_REQUEST_ID:
+		message = "IMPROPER REQUEST ID"
+	case TRANSACTION_NOT_PROCESSED:
+		message = "TRANSACTION NOT PROCESSED"
+	default:
+		message = fmt.Sprintf("VTPASS ERROR %s", e.Code)
 	}
 
 	return message
@@ -54,6 +142,7 @@ func (e ErrorResponse) Error() string {
 
 type WalletBalance struct {
 	BaseResponse
+	ResponseMeta
 	Contents struct {
 		Balance string `json:"balance"`
 	} `json:"contents"`
@@ -61,18 +150,21 @@ type WalletBalance struct {
 
 type ServiceCategoryResponse struct {
 	BaseResponse
+	ResponseMeta
 	Content             []ServiceCategory `json:"content"`
 	ResponseDescription string            `json:"response_description"`
 }
 
 type ServiceResponse struct {
 	BaseResponse
+	ResponseMeta
 	Content             []Service `json:"content"`
 	ResponseDescription string    `json:"response_description"`
 }
 
 type VariationResponse struct {
 	BaseResponse
+	ResponseMeta
 	Content struct {
 		ServiceName string      `json:"ServiceName"`
 		Variations  []Variation `json:"varations"`
@@ -80,34 +172,179 @@ type VariationResponse struct {
 }
 
 type CustomerInfoResponse struct {
-	Code    string       `json:"code"`
-	Content CustomerInfo `json:"content"`
+	Code       string          `json:"code"`
+	Content    CustomerInfo    `json:"content"`
+	Raw        json.RawMessage `json:"-"`
+	HTTPStatus int             `json:"-"`
 }
 
-func NewVTService(apiKey, publicKey, secretKey string, environment Environment) *VTService {
-	var baseUrl string
-
-	switch environment {
-	case EnvironmentSandbox:
-		baseUrl = SandboxBaseURL
-	case EnvironmentLive:
-		baseUrl = LiveEnviromentURL
-	default:
+// NewVTService returns a VTService for environment.
+//
+// Deprecated: an unknown environment, such as a misspelt configuration
+// value, falls back to sandbox with only a logged warning. Use
+// NewVTServiceStrict, which returns ErrUnknownEnvironment instead.
+func NewVTService(apiKey, publicKey, secretKey string, environment Environment, opts ...Option) *VTService {
+	baseUrl, err := environment.BaseURL()
+	if err != nil {
+		log.Printf("vtpass: %v, falling back to sandbox", err)
 		baseUrl = SandboxBaseURL
 	}
 
-	return &VTService{
-		apiKey:     apiKey,
-		client:     httpclient.NewAPIClient(baseUrl, apiKey),
+	s := &VTService{
 		Enviroment: environment,
-		publicKey:  publicKey,
-		secretKey:  secretKey,
-		authCredentials: map[string]string{
-			"api-key":    apiKey,
-			"public-key": publicKey,
-			"secret-key": secretKey,
+		baseURL:    baseUrl,
+		credentials: StaticCredentials{
+			APIKey:    apiKey,
+			PublicKey: publicKey,
+			SecretKey: secretKey,
 		},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.client = httpclient.NewAPIClient(baseUrl, apiKey, s.clientOptions...)
+
+	return s
+}
+
+// send makes an authenticated request. If VTPass rejects the credentials
+// with 087, the provider is refreshed and the request retried once, but
+// only when the refresh produced different credentials: replaying a
+// request with the same rejected keys would only duplicate it.
+func (s *VTService) send(ctx context.Context, method, path string, payload interface{}) (*http.Response, error) {
+	var creds Credentials
+	if s.credentials != nil {
+		var err error
+		if creds, err = s.credentials.Credentials(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.sendOnce(ctx, creds, method, path, payload)
+	if err != nil {
+		return resp, err
+	}
+	body, err := bufferBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var base BaseResponse
+	if s.credentials != nil && json.Unmarshal(body, &base) == nil && base.Code == INVALID_CREDENTIALS {
+		if refreshed, ok := s.refreshCredentials(ctx, creds); ok {
+			if resp, err = s.sendOnce(ctx, refreshed, method, path, payload); err != nil {
+				return nil, err
+			}
+			if body, err = bufferBody(resp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	captureRawResponse(ctx, resp, body)
+	return resp, nil
+}
+
+// refreshCredentials refreshes the provider and returns its credentials if
+// they differ from rejected.
+func (s *VTService) refreshCredentials(ctx context.Context, rejected Credentials) (Credentials, bool) {
+	if err := s.credentials.Refresh(ctx); err != nil {
+		return Credentials{}, false
+	}
+	creds, err := s.credentials.Credentials(ctx)
+	if err != nil || creds == rejected {
+		return Credentials{}, false
+	}
+	return creds, true
+}
+
+func (s *VTService) sendOnce(ctx context.Context, creds Credentials, method, path string, payload interface{}) (*http.Response, error) {
+	headers := map[string]string{}
+	if s.credentials != nil {
+		headers = creds.headers(method)
+	}
+
+	switch method {
+	case http.MethodGet:
+		return s.client.Get(ctx, path, headers)
+	case http.MethodPost:
+		return s.client.Post(ctx, path, payload, headers)
+	case http.MethodPut:
+		return s.client.Put(ctx, path, payload, headers)
+	case http.MethodPatch:
+		return s.client.Patch(ctx, path, payload, headers)
+	case http.MethodDelete:
+		return s.client.Delete(ctx, path, payload, headers)
+	}
+	return nil, fmt.Errorf("unsupported method %s", method)
+}
+
+// errorCodes are the VTPass codes that mean a request was rejected
+// outright. Codes describing a transaction, such as 016 or 015, are left
+// for the caller to interpret. 014 is a rejection too, but of the request
+// ID rather than the purchase; see IsDuplicateRequestID.
+var errorCodes = map[string]bool{
+	VARIATION_CODE_DOES_NOT_EXIST:      true,
+	INVALID_ARGUMENTS:                  true,
+	PRODUCT_DOES_NOT_EXIST:             true,
+	BELOW_MINIMUM_AMOUNT:               true,
+	REQUEST_ID_ALREADY_EXISTS:          true,
+	ABOVE_MAXIMUM_AMOUNT:               true,
+	LOW_WALLET_BALANCE:                 true,
+	LIKELY_DUPLICATE_TRANSACTION:       true,
+	ACCOUNT_LOCKED:                     true,
+	ACCOUNT_SUSPENDED:                  true,
+	API_ACCESS_NOT_ENABLED:             true,
+	ACCOUNT_INACTIVE:                   true,
+	RECIPIENT_BANK_INVALID:             true,
+	RECIPIENT_ACCOUNT_NOT_VERIFIED:     true,
+	IP_NOT_WHITELISTED:                 true,
+	PRODUCT_NOT_WHITELISTED:            true,
+	BILLER_NOT_REACHABLE_AT_THIS_POINT: true,
+	IMPROPER_REQUEST_ID:                true,
+	INVALID_CREDENTIALS:                true,
+	TRANSACTION_NOT_PROCESSED:          true,
+}
+
+// do sends a request and decodes the response into a T. A non-2xx status
+// or a VTPass error code is returned as an ErrorResponse carrying the raw
+// body, so every endpoint reports failures the same way.
+func do[T any](ctx context.Context, s *VTService, method, path string, payload interface{}) (*T, error) {
+	resp, err := s.send(ctx, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var base BaseResponse
+	decoded := json.Unmarshal(body, &base) == nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Gateways in front of VTPass may answer with HTML and no code.
+		if !decoded || base.Code == "" {
+			return nil, &HTTPError{StatusCode: resp.StatusCode, Body: body}
+		}
+		return nil, ErrorResponse{base}
+	}
+
+	// The code is checked first because error responses often carry a
+	// content field of a different shape than T expects.
+	if decoded && errorCodes[base.Code] {
+		return nil, ErrorResponse{base}
+	}
+
+	result := new(T)
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+	if r, ok := any(result).(rawRecorder); ok {
+		r.setRaw(resp.StatusCode, body)
+	}
+	return result, nil
 }
 
 type Details struct {
@@ -132,87 +369,132 @@ type TransactionResponse struct {
 	ResponseDescription string `json:"response_description"`
 	Content             TransactionContent `json:"content"`
 	RequestID           string           `json:"requestId"`
-	Amount              string           `json:"amount"`
-	TransactionDate     string  `json:"transaction_date"`
+	Amount              FlexFloat           `json:"amount"`
+	TransactionDate     TransactionDate  `json:"transaction_date"`
 	PurchasedCode       string           `json:"purchased_code"`
+	Raw                 json.RawMessage  `json:"-"`
+	HTTPStatus          int              `json:"-"`
 }
 
 // QUERY TRANSACTION STATUS
+// An unknown request ID is not an error: the response has code 015.
 func (s *VTService) QueryTransaction(ctx context.Context, request_id string) (*TransactionResponse, error) {
-	url := "requery"
-
 	payload := map[string]interface{}{
 		"request_id": request_id,
 	}
 
-	resp, err := s.client.Post(ctx, url, payload, s.authCredentials)
-	if err != nil {
-		return nil, err
-	}
+	return do[TransactionResponse](ctx, s, http.MethodPost, "requery", payload)
+}
 
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, err
-		}
-		return nil, errorResponse
-	}
+// PURCHASE PRODUCT (Payment)
+// https://www.vtpass.com/documentation/how-to-integrate-vtpass-api/
+func (s *VTService) Purchase(ctx context.Context, payload PurchaseRequest) (*PayResponse, error) {
+	url := "pay"
 
-	var resonse TransactionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
+	if err := s.checkLive(); err != nil {
 		return nil, err
 	}
+	s.warnSandboxNumbers(payload.BillersCode, payload.Phone)
 
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
+	if payload.RequestID == "" {
+		payload.RequestID = s.GenerateRequestID()
 	}
-	if resonse.Code == "012" {
-		return nil, fmt.Errorf("prodduct does not exist")
+	ctx, raw := withRawCapture(ctx)
+	if err := s.recordPurchase(ctx, payload); err != nil {
+		return nil, err
 	}
-
-
-	fmt.Println(resonse)
-	return &resonse, nil
-
-}
-
-// PURCHASE PRODUCT (Payment)
-// https://www.vtpass.com/documentation/eedc-enugu-electric-api/
-func (s *VTService) PurchaseElectricity(ctx context.Context, payload ElectricityPurchase) (*PayResponse, error) {
-
-	url := "pay"
-	resp, err := s.client.Post(ctx, url, payload, s.authCredentials)
-	if err != nil {
+	if err := s.markSubmitted(ctx, payload.RequestID); err != nil {
 		return nil, err
 	}
 
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
+	resonse, err := do[PayResponse](ctx, s, http.MethodPost, url, payload)
+	if err != nil {
+		// VTPass refused the purchase, so nothing was charged. Other
+		// errors, and a request ID VTPass has seen before, leave the
+		// outcome unknown until requeried.
 		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, errorResponse
+		if errors.As(err, &errorResponse) && !IsDuplicateRequestID(err) {
+			s.recordOutcome(ctx, payload.RequestID, StatusFailed, raw.Body, nil)
 		}
-	}
-
-	var resonse PayResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
 		return nil, err
 	}
 
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
+	txn := resonse.Content.Transactions
+	s.recordOutcome(ctx, payload.RequestID, resonse.Status(), resonse.Raw, &txn)
+
+	if purchaseStatus(resonse.Code, txn.Status) != BulkFailed {
+		spent := txn.TotalAmount.Float64()
+		if spent <= 0 {
+			spent = payload.Amount
+		}
+		s.recordSpend(NewMoney(spent))
 	}
-	if resonse.Code == "012" {
-		return nil, fmt.Errorf("prodduct does not exist")
+
+	return resonse, nil
+}
+
+// OnSpend registers fn to be called after every purchase that VTPass
+// accepts, with the amount debited from the wallet. Calling the returned
+// function unregisters fn.
+func (s *VTService) OnSpend(fn func(amount Money, at time.Time)) (unsubscribe func()) {
+	listener := &spendListener{fn: fn}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spendListeners = append(s.spendListeners, listener)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// recordSpend iterates over a copy of the slice header, so the
+		// remaining listeners go into a new slice.
+		kept := make([]*spendListener, 0, len(s.spendListeners))
+		for _, l := range s.spendListeners {
+			if l != listener {
+				kept = append(kept, l)
+			}
+		}
+		s.spendListeners = kept
 	}
-	if resonse.Code == "018" {
-		return nil, fmt.Errorf("balance low")
+}
+
+func (s *VTService) recordSpend(amount Money) {
+	s.mu.RLock()
+	listeners := s.spendListeners
+	s.mu.RUnlock()
+
+	now := time.Now()
+	for _, l := range listeners {
+		l.fn(amount, now)
 	}
-	fmt.Println(resonse)
+}
+
+// PURCHASE ELECTRICITY
+// https://www.vtpass.com/documentation/eedc-enugu-electric-api/
+func (s *VTService) PurchaseElectricity(ctx context.Context, payload ElectricityPurchase) (*PayResponse, error) {
+	return s.Purchase(ctx, PurchaseRequest{
+		RequestID:     payload.RequestID,
+		ServiceID:     payload.ServiceID,
+		BillersCode:   payload.BillersCode,
+		VariationCode: payload.VariationCode,
+		Amount:        payload.Amount,
+		Phone:         payload.Phone,
+	})
+}
 
-	return &resonse, nil
+// IsLowBalance reports whether err is VTPass's 018 low wallet balance error.
+func IsLowBalance(err error) bool {
+	var errorResponse ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Code == LOW_WALLET_BALANCE
+}
 
+// IsDuplicateRequestID reports whether err is VTPass's 014 error: the
+// request ID was used before. The purchase it names may have been paid, so
+// requery it instead of treating the purchase as refused.
+func IsDuplicateRequestID(err error) bool {
+	var errorResponse ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Code == REQUEST_ID_ALREADY_EXISTS
 }
 
 // REQUEST ID
@@ -242,43 +524,33 @@ func (s *VTService) GenerateRequestID() string {
 
 // VERIFY METER NUMBER
 // https://www.vtpass.com/documentation/eedc-enugu-electric-api/
-func (s *VTService) VerifyMeterNumber(ctx context.Context, meter_number, meter_type, service_id string) (*CustomerInfo, error) {
-	url := "merchant-verify"
-
-	requestData := map[string]interface{}{
-		"billersCode": meter_number,
-		"serviceID":   service_id,
-		"type":        meter_type,
-	}
-
-	resp, err := s.client.Post(ctx, url, requestData, s.authCredentials)
-	if err != nil {
-		log.Printf("request failed: %v", err)
+func (s *VTService) VerifyMeterNumber(ctx context.Context, meter_number string, meter_type MeterType, disco Disco) (*CustomerInfo, error) {
+	if err := disco.ValidateMeterNumber(meter_number, meter_type); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			log.Printf("decoding error response failed: %v", err)
-			return nil, errorResponse
-		}
-	}
+	return s.VerifyCustomer(ctx, string(disco), meter_number, string(meter_type))
+}
 
-	var resonse CustomerInfoResponse
-	fmt.Println(resp.StatusCode, resp.Status)
+// VERIFY CUSTOMER
+// VerifyCustomer checks a customer ID with the biller, e.g. a smartcard
+// number for serviceID "dstv". customerType is only sent when set; billers
+// such as electricity discos require it.
+func (s *VTService) VerifyCustomer(ctx context.Context, serviceID, billersCode, customerType string) (*CustomerInfo, error) {
+	url := "merchant-verify"
+	s.warnSandboxNumbers(billersCode)
 
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
-		log.Printf("decoding body failed: %v", err)
-		return nil, err
+	requestData := map[string]interface{}{
+		"billersCode": billersCode,
+		"serviceID":   serviceID,
 	}
-
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
+	if customerType != "" {
+		requestData["type"] = customerType
 	}
-	if resonse.Code == "012" {
-		return nil, fmt.Errorf("prodduct does not exist")
+
+	resonse, err := do[CustomerInfoResponse](ctx, s, http.MethodPost, url, requestData)
+	if err != nil {
+		return nil, err
 	}
 
 	return &resonse.Content, nil
@@ -290,30 +562,11 @@ func (s *VTService) VerifyMeterNumber(ctx context.Context, meter_number, meter_t
 func (s *VTService) ServiceVariations(ctx context.Context, id string) ([]Variation, error) {
 	url := fmt.Sprintf("service-variations?serviceID=%s", id)
 
-	resp, err := s.client.Get(ctx, url, s.authCredentials)
+	resonse, err := do[VariationResponse](ctx, s, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, err
-		}
-
-		return nil, errorResponse
-	}
-	var resonse VariationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
-		return nil, err
-	}
-
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
-	}
-
 	return resonse.Content.Variations, nil
 }
 
@@ -322,130 +575,30 @@ func (s *VTService) ServiceVariations(ctx context.Context, id string) ([]Variati
 func (s *VTService) ServiceByIdentifier(ctx context.Context, id string) ([]Service, error) {
 	url := fmt.Sprintf("services?identifier=%s", id)
 
-	resp, err := s.client.Get(ctx, url, s.authCredentials)
+	resonse, err := do[ServiceResponse](ctx, s, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, err
-		}
-
-		return nil, errorResponse
-	}
-	var resonse ServiceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
-		return nil, err
-	}
-	if resonse.Code == "010" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
-	}
 
 	return resonse.Content, nil
 }
 
 func (s *VTService) ServiceCategories(ctx context.Context) ([]ServiceCategory, error) {
-	resp, err := s.client.Get(ctx, "service-categories", s.authCredentials)
+	resonse, err := do[ServiceCategoryResponse](ctx, s, http.MethodGet, "service-categories", nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, err
-		}
-
-		return nil, errorResponse
-	}
-	var resonse ServiceCategoryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
-		return nil, err
-	}
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
-	}
 
 	return resonse.Content, nil
 }
 
-// Test authentication
+// Ping reports whether VTPass is reachable and accepts the credentials.
+// Use Health for the reason a check failed.
 func (s *VTService) Ping(ctx context.Context) (bool, error) {
-	resp, err := s.client.Get(ctx, "balance", s.authCredentials)
-	if err != nil {
-		return false, err
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return false, errorResponse
-		}
-	}
-	return true, nil
+	report := s.Health(ctx)
+	return report.Healthy(), report.Err
 }
 
 func (s *VTService) Balance(ctx context.Context) (*WalletBalance, error) {
-
-	// fmt.Println(s.authCredentials)
-	resp, err := s.client.Get(ctx, "balance", s.authCredentials)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return nil, errorResponse
-		}
-	}
-
-	var resonse WalletBalance
-	if err := json.NewDecoder(resp.Body).Decode(&resonse); err != nil {
-		return nil, err
-	}
-	if resonse.Code == "011" {
-		return nil, fmt.Errorf("service not valid or invalid argumments")
-	}
-	return &resonse, nil
-
+	return do[WalletBalance](ctx, s, http.MethodGet, "balance", nil)
 }
-
-// func (c *Service) PostData(ctx context.Context, payload interface{}) (*Response, error) {
-
-// 	path := fmt.Sprintf("user%s", c.authCredentials)
-
-// 	resp, err := c.client.Post(ctx, path, payload)
-
-// 	if err != nil {
-// 		fmt.Println(err.Error())
-// 		return nil, err
-// 	}
-
-// 	defer resp.Body.Close()
-
-// 	if resp.StatusCode != http.StatusOK {
-// 		var errorResponse ErrorResponse
-// 		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-// 			return nil, err
-// 		}
-
-// 		return nil, errorResponse
-// 	}
-
-// 	var response Response
-// 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-// 		fmt.Println("error decoding response", err)
-// 		return nil, err
-// 	}
-
-// 	return &response, nil
-// }
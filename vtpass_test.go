@@ -10,6 +10,7 @@ import (
 	httpclient "github.com/CeoFred/vtpass-go/lib"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPing(t *testing.T) {
@@ -35,11 +36,8 @@ func TestPing(t *testing.T) {
 
 	// Initialize service with mock client
 	service := &VTService{
-		apiKey: "test-api-key",
-		client: mockClient,
-		authCredentials: map[string]string{
-			"api-key": "test-api-key",
-		},
+		client:      mockClient,
+		credentials: StaticCredentials{APIKey: "test-api-key"},
 	}
 
 	// Call Balance
@@ -50,6 +48,85 @@ func TestPing(t *testing.T) {
 	assert.NotNil(t, resp)
 }
 
+func TestEndpointsMapErrorsUniformly(t *testing.T) {
+	var status int
+	var body string
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetGetFunc(func(ctx context.Context, path string) (*http.Response, error) {
+		return rawJSONResponse(status, body), nil
+	})
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return rawJSONResponse(status, body), nil
+	})
+	service := &VTService{client: mockClient}
+	var raw RawResponse
+	ctx := WithRawResponse(context.Background(), &raw)
+
+	calls := map[string]func() error{
+		"Ping":             func() error { _, err := service.Ping(ctx); return err },
+		"Balance":          func() error { _, err := service.Balance(ctx); return err },
+		"QueryTransaction": func() error { _, err := service.QueryTransaction(ctx, "abc"); return err },
+		"Purchase": func() error {
+			_, err := service.Purchase(ctx, PurchaseRequest{ServiceID: "mtn", Amount: 100, Phone: "08011111111"})
+			return err
+		},
+		"VerifyMeterNumber": func() error {
+			_, err := service.VerifyMeterNumber(ctx, "1111111111111", MeterTypePrepaid, DiscoIkeja)
+			return err
+		},
+		"ServiceVariations":   func() error { _, err := service.ServiceVariations(ctx, "mtn-data"); return err },
+		"ServiceByIdentifier": func() error { _, err := service.ServiceByIdentifier(ctx, "airtime"); return err },
+		"ServiceCategories":   func() error { _, err := service.ServiceCategories(ctx); return err },
+	}
+
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+	}{
+		{"non-200 with decodable body", http.StatusInternalServerError, `{"content":{}}`, ""},
+		{"non-200 with HTML body", http.StatusBadGateway, `<html>bad gateway</html>`, ""},
+		{"invalid arguments", http.StatusOK, `{"code":"011","content":{}}`, INVALID_ARGUMENTS},
+		{"product does not exist", http.StatusOK, `{"code":"012","content":{}}`, PRODUCT_DOES_NOT_EXIST},
+		{"invalid credentials", http.StatusOK, `{"code":"087","content":{}}`, INVALID_CREDENTIALS},
+	}
+	for _, tc := range cases {
+		status, body = tc.status, tc.body
+		for name, call := range calls {
+			raw = RawResponse{}
+			err := call()
+			assert.Equal(t, tc.status, raw.HTTPStatus, "%s: %s", tc.name, name)
+			assert.Equal(t, tc.body, string(raw.Body), "%s: %s", tc.name, name)
+
+			if tc.wantCode == "" {
+				var httpErr *HTTPError
+				if assert.ErrorAs(t, err, &httpErr, "%s: %s", tc.name, name) {
+					assert.Equal(t, tc.status, httpErr.StatusCode, "%s: %s", tc.name, name)
+				}
+				continue
+			}
+			var errorResponse ErrorResponse
+			if assert.ErrorAs(t, err, &errorResponse, "%s: %s", tc.name, name) {
+				assert.Equal(t, tc.wantCode, errorResponse.Code, "%s: %s", tc.name, name)
+				assert.NotEmpty(t, errorResponse.Error())
+			}
+		}
+	}
+}
+
+func TestQueryTransactionUnknownRequestID(t *testing.T) {
+	mockClient := httpclient.NewMockClient()
+	mockClient.SetPostFunc(func(ctx context.Context, path string, payload interface{}) (*http.Response, error) {
+		return rawJSONResponse(http.StatusOK, `{"code":"015","response_description":"INVALID REQUEST ID"}`), nil
+	})
+	service := &VTService{client: mockClient}
+
+	resp, err := service.QueryTransaction(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Equal(t, INVALID_REQUEST_ID, resp.Code)
+}
+
 // func TestPostData(t *testing.T) {
 // 	// Mock response
 // 	mockResponse := Response{